@@ -1,6 +1,10 @@
 package behavioral
 
-import "container/list"
+import (
+	"container/list"
+	"context"
+	"reflect"
+)
 
 type Query[D interface{}, R interface{}] struct {
 	Data   D
@@ -12,30 +16,76 @@ type Handler[D interface{}, R interface{}] interface {
 	Handle(*Query[D, R])
 }
 
+// ContextHandler is a Handler receiving the context given to FireContext, Fire give it context.Background()
+type ContextHandler[D interface{}, R interface{}] interface {
+	Handler[D, R]
+	HandleContext(ctx context.Context, q *Query[D, R])
+}
+
 type Broker[D interface{}, R interface{}] struct {
-	Handlers list.List
+	Handlers    list.List
+	panicPolicy PanicPolicy
 }
 
 func (b *Broker[D, R]) Subscribe(o Handler[D, R]) {
-	b.Handlers.PushBack(o)
+	b.insert(&registration[D, R]{Handler: o})
 }
 
+// Unsubscribe remove every subscription of the handler, handlers that cannot be compared
+// such as a HandlerFunc are removed by name with Remove
 func (b *Broker[D, R]) Unsubscribe(o Handler[D, R]) {
-	for s := b.Handlers.Front(); s != nil; s = s.Next() {
-		if s.Value.(Handler[D, R]) == o {
+	if t := reflect.TypeOf(o); t == nil || !t.Comparable() {
+		return
+	}
+	for s := b.Handlers.Front(); s != nil; {
+		next := s.Next() // Next is nil once the element is removed
+		if registrationOf[D, R](s).Handler == o {
 			b.Handlers.Remove(s)
 		}
+		s = next
 	}
 }
 
 func (b *Broker[D, R]) Fire(q *Query[D, R]) {
+	b.FireContext(context.Background(), q)
+}
+
+// FireContext run the handlers in order until one of them set the query error or the context is done,
+// ctx.Err() is then recorded as the query error and the remaining handlers are skipped. A context done
+// during the last handler is recorded as well
+func (b *Broker[D, R]) FireContext(ctx context.Context, q *Query[D, R]) {
 
 	for s := b.Handlers.Front(); s != nil; s = s.Next() {
-		s.Value.(Handler[D, R]).Handle(q)
-		if q.Error != nil {
+		if !step(ctx, b.panicPolicy, s.Value.(Handler[D, R]), q) {
 			return
 		}
 	}
+	checkDone(ctx, q)
+}
+
+// checkDone record ctx.Err() as the query error once every handler succeeded
+func checkDone[D interface{}, R interface{}](ctx context.Context, q *Query[D, R]) {
+	if err := ctx.Err(); err != nil {
+		q.Error = err
+	}
+}
+
+// step run a handler of a chain, false when the chain must stop
+func step[D interface{}, R interface{}](ctx context.Context, policy PanicPolicy, handler Handler[D, R], q *Query[D, R]) bool {
+	if err := ctx.Err(); err != nil {
+		q.Error = err
+		return false
+	}
+	protect(policy, ctx, handler, q)
+	return q.Error == nil
+}
+
+func handle[D interface{}, R interface{}](ctx context.Context, handler Handler[D, R], q *Query[D, R]) {
+	if contextHandler, ok := handler.(ContextHandler[D, R]); ok {
+		contextHandler.HandleContext(ctx, q)
+		return
+	}
+	handler.Handle(q)
 }
 
 func NewBroker[D interface{}, R interface{}]() *Broker[D, R] {
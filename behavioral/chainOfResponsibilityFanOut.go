@@ -0,0 +1,205 @@
+package behavioral
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type fanOutKind int
+
+const (
+	firstSuccess fanOutKind = iota
+	allMustSucceed
+	quorum
+	collectErrors
+)
+
+// FanOutPolicy decide when a FanOutBroker stop waiting for its handlers and whether the query failed
+type FanOutPolicy struct {
+	kind   fanOutKind
+	quorum int
+}
+
+var (
+	FirstSuccess   = FanOutPolicy{kind: firstSuccess}   // The first successful result is kept, the query fail when every handler fail
+	AllMustSucceed = FanOutPolicy{kind: allMustSucceed} // The query fail with the first error
+	CollectErrors  = FanOutPolicy{kind: collectErrors}  // Every handler is awaited, the successful results are reduced and the errors joined
+)
+
+// Quorum wait for n successful results, the query fail with a QuorumError once n successes are out of reach.
+// Quorum panic when n is not positive
+func Quorum(n int) FanOutPolicy {
+	if n <= 0 {
+		panic(strings.ToUpper(fmt.Sprintf("Quorum of %d is Not Positive", n)))
+	}
+	return FanOutPolicy{kind: quorum, quorum: n}
+}
+
+// required return the number of successes ending the fan out
+func (policy FanOutPolicy) required(handlers int) int {
+	switch policy.kind {
+	case firstSuccess:
+		return 1
+	case quorum:
+		return policy.quorum
+	}
+	return handlers
+}
+
+// Reducer combine the partial results of the successful handlers, given in subscription order
+type Reducer[R interface{}] func(results []R) R
+
+type QuorumError struct {
+	Required, Succeeded int
+	Err                 error
+}
+
+func (e *QuorumError) Error() string {
+
+	message := strings.ToUpper(fmt.Sprintf("Quorum of %d Not Reached, %d Succeeded", e.Required, e.Succeeded))
+	if e.Err == nil {
+		return message
+	}
+	return fmt.Sprintf("%s: %s", message, e.Err)
+}
+
+func (e *QuorumError) Unwrap() error {
+	return e.Err
+}
+
+// NotEnoughHandlersError is recorded without running the handlers when fewer handlers are subscribed than the successes required
+type NotEnoughHandlersError struct {
+	Required, Subscribed int
+}
+
+func (e *NotEnoughHandlersError) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("%d Successes Required but %d Handlers Subscribed", e.Required, e.Subscribed))
+}
+
+// FanOutBroker run every handler concurrently on its own copy of the query, each one producing a partial result.
+// The context given to the handlers is cancelled once the policy is decided
+type FanOutBroker[D interface{}, R interface{}] struct {
+	Handlers    []Handler[D, R]
+	policy      FanOutPolicy
+	reducer     Reducer[R]
+	panicPolicy PanicPolicy
+}
+
+// NewFanOutBroker create a broker combining the partial results with the reducer, a nil reducer keep the first result
+func NewFanOutBroker[D interface{}, R interface{}](policy FanOutPolicy, reducer Reducer[R]) *FanOutBroker[D, R] {
+	return &FanOutBroker[D, R]{policy: policy, reducer: reducer}
+}
+
+func (b *FanOutBroker[D, R]) Subscribe(o Handler[D, R]) {
+	b.Handlers = append(b.Handlers, o)
+}
+
+func (b *FanOutBroker[D, R]) Fire(q *Query[D, R]) {
+	b.FireContext(context.Background(), q)
+}
+
+type partialResult[R interface{}] struct {
+	index     int
+	result    R
+	err       error
+	recovered *HandlerPanicError
+}
+
+func (b *FanOutBroker[D, R]) FireContext(ctx context.Context, q *Query[D, R]) {
+	required := b.policy.required(len(b.Handlers))
+	if len(b.Handlers) < required {
+		q.Error = &NotEnoughHandlersError{required, len(b.Handlers)}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	data, initial := q.Data, q.Result
+	partials := make(chan partialResult[R], len(b.Handlers))
+	for i, handler := range b.Handlers {
+		go func(i int, handler Handler[D, R]) {
+			policy := b.panicPolicy
+			if policy == PropagatePanic {
+				policy = RecoverPanic // Panicking on the worker would kill the process, the panic is raised again by FireContext
+			}
+			partial := &Query[D, R]{Data: data, Result: initial}
+			recovered := protect(policy, ctx, handler, partial)
+			if recovered != nil {
+				partial.Error = recovered
+			}
+			partials <- partialResult[R]{i, partial.Result, partial.Error, recovered}
+		}(i, handler)
+	}
+
+	successes, failures := []partialResult[R]{}, []partialResult[R]{}
+	for len(successes)+len(failures) < len(b.Handlers) {
+		var partial partialResult[R]
+		select {
+		case partial = <-partials:
+		case <-ctx.Done():
+			q.Error = ctx.Err()
+			return
+		}
+		if partial.recovered != nil && b.panicPolicy == PropagatePanic {
+			panic(partial.recovered)
+		}
+
+		if partial.err == nil {
+			successes = append(successes, partial)
+			if b.policy.kind != collectErrors && len(successes) == required {
+				break
+			}
+			continue
+		}
+		failures = append(failures, partial)
+		if b.policy.kind == allMustSucceed {
+			q.Error = partial.err
+			return
+		}
+		if b.policy.kind != collectErrors && len(b.Handlers)-len(failures) < required {
+			break
+		}
+	}
+
+	if b.policy.kind != collectErrors && len(successes) < required {
+		err := joinPartialErrors(failures)
+		if b.policy.kind == quorum {
+			err = &QuorumError{required, len(successes), err}
+		}
+		q.Error = err
+		return
+	}
+	if len(successes) > 0 {
+		q.Result = b.reduce(successes)
+	}
+	if b.policy.kind == collectErrors {
+		q.Error = joinPartialErrors(failures)
+	}
+}
+
+func (b *FanOutBroker[D, R]) reduce(successes []partialResult[R]) R {
+	sort.Slice(successes, func(i, j int) bool { return successes[i].index < successes[j].index })
+	if b.reducer == nil {
+		return successes[0].result
+	}
+	results := make([]R, len(successes))
+	for i, success := range successes {
+		results[i] = success.result
+	}
+	return b.reducer(results)
+}
+
+// joinPartialErrors join the errors in subscription order
+func joinPartialErrors[R interface{}](failures []partialResult[R]) error {
+	sort.Slice(failures, func(i, j int) bool { return failures[i].index < failures[j].index })
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		errs[i] = failure.err
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,52 @@
+package behavioral
+
+import "context"
+
+// HandlerFunc is a Handler made of a function
+type HandlerFunc[D interface{}, R interface{}] func(q *Query[D, R])
+
+func (f HandlerFunc[D, R]) Handle(q *Query[D, R]) {
+	f(q)
+}
+
+// ContextHandlerFunc is a ContextHandler made of a function, Handle give it context.Background()
+type ContextHandlerFunc[D interface{}, R interface{}] func(ctx context.Context, q *Query[D, R])
+
+func (f ContextHandlerFunc[D, R]) Handle(q *Query[D, R]) {
+	f(context.Background(), q)
+}
+
+func (f ContextHandlerFunc[D, R]) HandleContext(ctx context.Context, q *Query[D, R]) {
+	f(ctx, q)
+}
+
+// RequireThat return a handler failing the query with err when the predicate is false
+func RequireThat[D interface{}, R interface{}](predicate func(q *Query[D, R]) bool, err error) Handler[D, R] {
+	return HandlerFunc[D, R](func(q *Query[D, R]) {
+		if !predicate(q) {
+			q.Error = err
+		}
+	})
+}
+
+// Transform return a handler replacing the result by the one computed from the data and the current result,
+// the result is kept when the transformer fail
+func Transform[D interface{}, R interface{}](transformer func(data D, result R) (R, error)) Handler[D, R] {
+	return HandlerFunc[D, R](func(q *Query[D, R]) {
+		result, err := transformer(q.Data, q.Result)
+		if err != nil {
+			q.Error = err
+			return
+		}
+		q.Result = result
+	})
+}
+
+// When return a handler running h only when the predicate is true, the context is given to h
+func When[D interface{}, R interface{}](predicate func(q *Query[D, R]) bool, h Handler[D, R]) Handler[D, R] {
+	return ContextHandlerFunc[D, R](func(ctx context.Context, q *Query[D, R]) {
+		if predicate(q) {
+			handle(ctx, h, q)
+		}
+	})
+}
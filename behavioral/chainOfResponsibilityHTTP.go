@@ -0,0 +1,99 @@
+package behavioral
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// QueryFirer run a chain on a query, Broker, SyncBroker, FanOutBroker and Chain are query firers
+type QueryFirer[D interface{}, R interface{}] interface {
+	FireContext(ctx context.Context, q *Query[D, R])
+}
+
+// StatusMapper choose the HTTP status answered for the error of a query
+type StatusMapper func(err error) int
+
+// HTTPStatusError let a handler choose the HTTP status answered by the middleware
+type HTTPStatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *HTTPStatusError) Error() string {
+
+	return fmt.Sprintf("%s: %s", strings.ToUpper(fmt.Sprintf("Status %d %s", e.Status, http.StatusText(e.Status))), e.Err)
+}
+
+func (e *HTTPStatusError) Unwrap() error {
+	return e.Err
+}
+
+type RequestExtractionError struct {
+	Err error
+}
+
+func (e *RequestExtractionError) Error() string {
+
+	return fmt.Sprintf("%s: %s", strings.ToUpper("Invalid Request"), e.Err)
+}
+
+func (e *RequestExtractionError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultStatusMapper answer the status of an HTTPStatusError, 400 when the request could not be extracted,
+// 500 for a recovered panic, 504 when the deadline of the request passed and 403 otherwise
+func DefaultStatusMapper(err error) int {
+	var statusErr *HTTPStatusError
+	var extractionErr *RequestExtractionError
+	var panicErr *HandlerPanicError
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Status
+	case errors.As(err, &extractionErr):
+		return http.StatusBadRequest
+	case errors.As(err, &panicErr):
+		return http.StatusInternalServerError
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusForbidden
+}
+
+type queryResultKey[R interface{}] struct{}
+
+// QueryResultFromContext return the result stored by the middleware for the downstream handlers
+func QueryResultFromContext[R interface{}](ctx context.Context) (R, bool) {
+	result, ok := ctx.Value(queryResultKey[R]{}).(R)
+	return result, ok
+}
+
+// NewHTTPMiddleware run the chain on the query extracted from each request, with the context of the request.
+// On error the status given by the mapper is answered with its status text and the next handler is not called,
+// otherwise the result is stored in the request context, see QueryResultFromContext. A nil mapper use DefaultStatusMapper
+func NewHTTPMiddleware[D interface{}, R interface{}](chain QueryFirer[D, R], extract func(r *http.Request) (D, error), mapper StatusMapper) func(http.Handler) http.Handler {
+	if mapper == nil {
+		mapper = DefaultStatusMapper
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := Query[D, R]{}
+			if data, err := extract(r); err != nil {
+				q.Error = &RequestExtractionError{err}
+			} else {
+				q.Data = data
+				chain.FireContext(r.Context(), &q)
+			}
+
+			if q.Error != nil {
+				status := mapper(q.Error)
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), queryResultKey[R]{}, q.Result)))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package behavioral
+
+import "context"
+
+// Middleware is a step of a Chain, it run the rest of the chain by calling next and can run code
+// once the rest of the chain returned. Not calling next end the chain, successfully unless the query error is set
+type Middleware[D interface{}, R interface{}] interface {
+	Handle(q *Query[D, R], next func())
+}
+
+// ContextMiddleware is a Middleware receiving the context given to FireContext
+type ContextMiddleware[D interface{}, R interface{}] interface {
+	Middleware[D, R]
+	HandleContext(ctx context.Context, q *Query[D, R], next func())
+}
+
+type MiddlewareFunc[D interface{}, R interface{}] func(q *Query[D, R], next func())
+
+func (f MiddlewareFunc[D, R]) Handle(q *Query[D, R], next func()) {
+	f(q, next)
+}
+
+// Chain run middlewares in order, each one wrapping the ones after it. A Chain is a Handler
+// and can be subscribed to a Broker, a Broker become a middleware with BrokerMiddleware
+type Chain[D interface{}, R interface{}] struct {
+	middlewares []Middleware[D, R]
+}
+
+func NewChain[D interface{}, R interface{}](middlewares ...Middleware[D, R]) *Chain[D, R] {
+	return &Chain[D, R]{middlewares: middlewares}
+}
+
+func (c *Chain[D, R]) Use(m Middleware[D, R]) {
+	c.middlewares = append(c.middlewares, m)
+}
+
+// UseHandler add a Handler to the chain, the rest of the chain is skipped when it set the query error
+func (c *Chain[D, R]) UseHandler(h Handler[D, R]) {
+	c.Use(&handlerMiddleware[D, R]{h})
+}
+
+func (c *Chain[D, R]) Handle(q *Query[D, R]) {
+	c.FireContext(context.Background(), q)
+}
+
+func (c *Chain[D, R]) HandleContext(ctx context.Context, q *Query[D, R]) {
+	c.FireContext(ctx, q)
+}
+
+func (c *Chain[D, R]) Fire(q *Query[D, R]) {
+	c.FireContext(context.Background(), q)
+}
+
+// FireContext run the chain, a middleware is not run once the context is done: ctx.Err() is recorded
+// as the query error instead and the middlewares waiting for next see it when next return.
+// As with a Broker, a context done while the chain ran successfully is recorded once the chain returned
+func (c *Chain[D, R]) FireContext(ctx context.Context, q *Query[D, R]) {
+	var run func(i int)
+	run = func(i int) {
+		if i == len(c.middlewares) {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			q.Error = err
+			return
+		}
+		next := func() { run(i + 1) }
+		if contextMiddleware, ok := c.middlewares[i].(ContextMiddleware[D, R]); ok {
+			contextMiddleware.HandleContext(ctx, q, next)
+			return
+		}
+		c.middlewares[i].Handle(q, next)
+	}
+	run(0)
+	if q.Error == nil {
+		checkDone(ctx, q)
+	}
+}
+
+type handlerMiddleware[D interface{}, R interface{}] struct {
+	handler Handler[D, R]
+}
+
+func (m *handlerMiddleware[D, R]) Handle(q *Query[D, R], next func()) {
+	m.HandleContext(context.Background(), q, next)
+}
+
+func (m *handlerMiddleware[D, R]) HandleContext(ctx context.Context, q *Query[D, R], next func()) {
+	handle(ctx, m.handler, q)
+	if q.Error == nil {
+		next()
+	}
+}
+
+// brokerHandler run a broker as a single handler
+type brokerHandler[D interface{}, R interface{}] struct {
+	broker *Broker[D, R]
+}
+
+func (h *brokerHandler[D, R]) Handle(q *Query[D, R]) {
+	h.broker.Fire(q)
+}
+
+func (h *brokerHandler[D, R]) HandleContext(ctx context.Context, q *Query[D, R]) {
+	h.broker.FireContext(ctx, q)
+}
+
+// BrokerMiddleware run the handlers of the broker as a step of a chain, the rest of the chain is skipped on error
+func BrokerMiddleware[D interface{}, R interface{}](b *Broker[D, R]) Middleware[D, R] {
+	return &handlerMiddleware[D, R]{&brokerHandler[D, R]{b}}
+}
@@ -0,0 +1,84 @@
+package behavioral
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// PanicPolicy decide what a broker do when a handler panic
+type PanicPolicy int
+
+const (
+	PropagatePanic       PanicPolicy = iota // The panic go through the broker, this is the default
+	RecoverPanic                            // The panic is recorded as a HandlerPanicError in the query error, stopping the chain
+	SkipPanickingHandler                    // The query is restored as it was before the handler and the chain continue
+)
+
+// HandlerPanicError is a recovered panic of a handler, Handler is the name of the handler or its type when it has no name
+type HandlerPanicError struct {
+	Handler string
+	Value   interface{}
+	Stack   []byte
+}
+
+func (e *HandlerPanicError) Error() string {
+
+	return fmt.Sprintf("%s: %v", strings.ToUpper(fmt.Sprintf("Handler %s Panicked", e.Handler)), e.Value)
+}
+
+// Unwrap return the panic value when it is an error
+func (e *HandlerPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func handlerName[D interface{}, R interface{}](handler Handler[D, R]) string {
+	if r, ok := handler.(*registration[D, R]); ok {
+		if r.name != "" {
+			return r.name
+		}
+		return fmt.Sprintf("%T", r.Handler)
+	}
+	return fmt.Sprintf("%T", handler)
+}
+
+// protect run the handler applying the panic policy, the recovered panic is returned
+func protect[D interface{}, R interface{}](policy PanicPolicy, ctx context.Context, handler Handler[D, R], q *Query[D, R]) (recovered *HandlerPanicError) {
+	if policy == PropagatePanic {
+		handle(ctx, handler, q)
+		return nil
+	}
+
+	saved := *q
+	defer func() {
+		if value := recover(); value != nil {
+			recovered = &HandlerPanicError{handlerName(handler), value, debug.Stack()}
+			*q = saved
+			if policy == RecoverPanic {
+				q.Error = recovered
+			}
+		}
+	}()
+	handle(ctx, handler, q)
+	return nil
+}
+
+func (b *Broker[D, R]) SetPanicPolicy(policy PanicPolicy) {
+	b.panicPolicy = policy
+}
+
+func (b *SyncBroker[D, R]) SetPanicPolicy(policy PanicPolicy) {
+	b.Update(func(broker *Broker[D, R]) error {
+		broker.SetPanicPolicy(policy)
+		return nil
+	})
+}
+
+// SetPanicPolicy apply the policy to each handler, a skipped handler count as failed with its HandlerPanicError.
+// The handlers run on their own goroutines: with PropagatePanic, FireContext panic on the caller goroutine
+// with the HandlerPanicError, a panic happening once the policy is decided is dropped
+func (b *FanOutBroker[D, R]) SetPanicPolicy(policy PanicPolicy) {
+	b.panicPolicy = policy
+}
@@ -0,0 +1,158 @@
+package behavioral
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// HandlerRegistration describe a handler of the pipeline, Name is empty for handlers subscribed without name
+type HandlerRegistration[D interface{}, R interface{}] struct {
+	Name     string
+	Priority int
+	Handler  Handler[D, R]
+}
+
+// registration is stored in the Handlers list in place of the handler it wraps
+type registration[D interface{}, R interface{}] struct {
+	Handler[D, R]
+	name     string
+	priority int
+}
+
+func (r *registration[D, R]) HandleContext(ctx context.Context, q *Query[D, R]) {
+	handle(ctx, r.Handler, q)
+}
+
+type UnknownHandlerError struct {
+	Name string
+}
+
+func (e *UnknownHandlerError) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("Handler %s is Unknown", e.Name))
+}
+
+type DuplicateHandlerError struct {
+	Name string
+}
+
+func (e *DuplicateHandlerError) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("Handler %s is Already Subscribed", e.Name))
+}
+
+// registrationOf return the registration of an element, handlers pushed directly into the Handlers list are anonymous
+func registrationOf[D interface{}, R interface{}](element *list.Element) *registration[D, R] {
+	if r, ok := element.Value.(*registration[D, R]); ok {
+		return r
+	}
+	return &registration[D, R]{Handler: element.Value.(Handler[D, R])}
+}
+
+// find return the element of the named handler, anonymous handlers are never found
+func (b *Broker[D, R]) find(name string) *list.Element {
+	if name == "" {
+		return nil
+	}
+	for s := b.Handlers.Front(); s != nil; s = s.Next() {
+		if registrationOf[D, R](s).name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// insert the registration after the handlers of the same or a higher priority
+func (b *Broker[D, R]) insert(r *registration[D, R]) {
+	for s := b.Handlers.Front(); s != nil; s = s.Next() {
+		if registrationOf[D, R](s).priority < r.priority {
+			b.Handlers.InsertBefore(r, s)
+			return
+		}
+	}
+	b.Handlers.PushBack(r)
+}
+
+// checkName refuse a name already used, empty names are anonymous and never conflict
+func (b *Broker[D, R]) checkName(name string) error {
+	if name != "" && b.find(name) != nil {
+		return &DuplicateHandlerError{name}
+	}
+	return nil
+}
+
+// SubscribeNamed add a handler with a name and a priority, handlers run from the highest priority
+// and in subscription order for a same priority. Subscribe use the priority 0
+func (b *Broker[D, R]) SubscribeNamed(name string, priority int, o Handler[D, R]) error {
+	if err := b.checkName(name); err != nil {
+		return err
+	}
+	b.insert(&registration[D, R]{o, name, priority})
+	return nil
+}
+
+// InsertBefore add a handler running just before the named one, with the same priority
+func (b *Broker[D, R]) InsertBefore(name string, newName string, o Handler[D, R]) error {
+	mark := b.find(name)
+	if mark == nil {
+		return &UnknownHandlerError{name}
+	}
+	if err := b.checkName(newName); err != nil {
+		return err
+	}
+	b.Handlers.InsertBefore(&registration[D, R]{o, newName, registrationOf[D, R](mark).priority}, mark)
+	return nil
+}
+
+// InsertAfter add a handler running just after the named one, with the same priority
+func (b *Broker[D, R]) InsertAfter(name string, newName string, o Handler[D, R]) error {
+	mark := b.find(name)
+	if mark == nil {
+		return &UnknownHandlerError{name}
+	}
+	if err := b.checkName(newName); err != nil {
+		return err
+	}
+	b.Handlers.InsertAfter(&registration[D, R]{o, newName, registrationOf[D, R](mark).priority}, mark)
+	return nil
+}
+
+// Replace the named handler in place, keeping its name and priority
+func (b *Broker[D, R]) Replace(name string, o Handler[D, R]) error {
+	element := b.find(name)
+	if element == nil {
+		return &UnknownHandlerError{name}
+	}
+	r := registrationOf[D, R](element)
+	element.Value = &registration[D, R]{o, r.name, r.priority}
+	return nil
+}
+
+// Remove the named handler, Unsubscribe remove a handler by equality
+func (b *Broker[D, R]) Remove(name string) error {
+	element := b.find(name)
+	if element == nil {
+		return &UnknownHandlerError{name}
+	}
+	b.Handlers.Remove(element)
+	return nil
+}
+
+// Pipeline list the handlers in the order they run
+func (b *Broker[D, R]) Pipeline() []HandlerRegistration[D, R] {
+	pipeline := []HandlerRegistration[D, R]{}
+	for s := b.Handlers.Front(); s != nil; s = s.Next() {
+		r := registrationOf[D, R](s)
+		pipeline = append(pipeline, HandlerRegistration[D, R]{r.name, r.priority, r.Handler})
+	}
+	return pipeline
+}
+
+func (r HandlerRegistration[D, R]) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%T (%d)", r.Handler, r.Priority)
+	}
+	return fmt.Sprintf("%s (%d)", r.Name, r.Priority)
+}
@@ -0,0 +1,118 @@
+package behavioral
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy define how many times and how long apart a failing handler is retried
+type RetryPolicy struct {
+	MaxAttempts    int                  // Attempts including the first one, a single attempt is made when it is not positive
+	InitialBackoff time.Duration        // Wait after the first failure
+	MaxBackoff     time.Duration        // Limit of the wait, no limit when it is not set
+	Multiplier     float64              // Growth of the wait after each failure, 2 when it is not set
+	Jitter         float64              // Part of the wait randomized, 0.2 spread it between 80% and 120%
+	Retryable      func(err error) bool // Errors worth a retry, every error when it is not set
+	Sleeper        Sleeper              // SystemClock when it is not set
+	Random         func() float64       // Source of the jitter in [0, 1), rand.Float64 when it is not set
+}
+
+// Backoff return the wait after the given failed attempt, starting at 1, the jitter never exceed MaxBackoff
+func (policy RetryPolicy) Backoff(attempt int) time.Duration {
+	if policy.InitialBackoff <= 0 {
+		return 0
+	}
+	multiplier := policy.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	backoff := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.Jitter > 0 {
+		random := policy.Random
+		if random == nil {
+			random = rand.Float64
+		}
+		backoff *= 1 + policy.Jitter*(2*random()-1)
+	}
+	if policy.MaxBackoff > 0 && backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+	if backoff >= math.MaxInt64 { // Converting a larger float to a Duration overflow
+		return math.MaxInt64
+	}
+	return time.Duration(backoff)
+}
+
+func (policy RetryPolicy) retryable(err error) bool {
+	return policy.Retryable == nil || policy.Retryable(err)
+}
+
+func (policy RetryPolicy) sleeper() Sleeper {
+	if policy.Sleeper == nil {
+		return SystemClock{}
+	}
+	return policy.Sleeper
+}
+
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+
+	return fmt.Sprintf("%s: %s", strings.ToUpper(fmt.Sprintf("Handler Failed after %d Attempts", e.Attempts)), e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// RetryHandler retry a handler following the policy, each attempt start from the query as it was given.
+// When the handler still fail, or fail with an error that is not retryable, the fallback handle the query,
+// or a RetryError is recorded when there is no fallback. The wait between attempts end with the context
+type RetryHandler[D interface{}, R interface{}] struct {
+	Handler  Handler[D, R]
+	Policy   RetryPolicy
+	Fallback Handler[D, R]
+}
+
+func NewRetryHandler[D interface{}, R interface{}](handler Handler[D, R], policy RetryPolicy, fallback Handler[D, R]) *RetryHandler[D, R] {
+	return &RetryHandler[D, R]{handler, policy, fallback}
+}
+
+func (h *RetryHandler[D, R]) Handle(q *Query[D, R]) {
+	h.HandleContext(context.Background(), q)
+}
+
+func (h *RetryHandler[D, R]) HandleContext(ctx context.Context, q *Query[D, R]) {
+	initial := q.Result
+	attempt := 1
+	var err error
+	for ; ; attempt++ {
+		attemptQuery := &Query[D, R]{Data: q.Data, Result: initial}
+		handle(ctx, h.Handler, attemptQuery)
+		if attemptQuery.Error == nil {
+			q.Result = attemptQuery.Result
+			return
+		}
+		err = attemptQuery.Error
+		if attempt >= h.Policy.MaxAttempts || !h.Policy.retryable(err) {
+			break
+		}
+		if sleepErr := h.Policy.sleeper().Sleep(ctx, h.Policy.Backoff(attempt)); sleepErr != nil {
+			q.Error = sleepErr
+			return
+		}
+	}
+
+	if h.Fallback == nil {
+		q.Error = &RetryError{attempt, err}
+		return
+	}
+	handle(ctx, h.Fallback, q)
+}
@@ -0,0 +1,108 @@
+package behavioral
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncBroker is a Broker safe for concurrent use. The handlers are copied on each subscription change
+// and Fire run on the copy current when it starts: a subscription made during a Fire apply to the next ones,
+// and firing never wait for a subscription. The zero value is ready to use
+type SyncBroker[D interface{}, R interface{}] struct {
+	mutex    sync.Mutex
+	broker   Broker[D, R]
+	snapshot atomic.Pointer[brokerSnapshot[D, R]]
+}
+
+// brokerSnapshot is the configuration read by Fire
+type brokerSnapshot[D interface{}, R interface{}] struct {
+	handlers    []Handler[D, R]
+	panicPolicy PanicPolicy
+}
+
+func NewSyncBroker[D interface{}, R interface{}]() *SyncBroker[D, R] {
+	return &SyncBroker[D, R]{}
+}
+
+// Update run fn with exclusive access to the registrations, the new handlers are published once fn returned
+func (b *SyncBroker[D, R]) Update(fn func(broker *Broker[D, R]) error) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	defer b.publish()
+	return fn(&b.broker)
+}
+
+// publish the copy of the handlers read by Fire, the mutex must be held
+func (b *SyncBroker[D, R]) publish() {
+	snapshot := &brokerSnapshot[D, R]{make([]Handler[D, R], 0, b.broker.Handlers.Len()), b.broker.panicPolicy}
+	for s := b.broker.Handlers.Front(); s != nil; s = s.Next() {
+		snapshot.handlers = append(snapshot.handlers, s.Value.(Handler[D, R]))
+	}
+	b.snapshot.Store(snapshot)
+}
+
+func (b *SyncBroker[D, R]) Subscribe(o Handler[D, R]) {
+	b.Update(func(broker *Broker[D, R]) error {
+		broker.Subscribe(o)
+		return nil
+	})
+}
+
+func (b *SyncBroker[D, R]) Unsubscribe(o Handler[D, R]) {
+	b.Update(func(broker *Broker[D, R]) error {
+		broker.Unsubscribe(o)
+		return nil
+	})
+}
+
+func (b *SyncBroker[D, R]) SubscribeNamed(name string, priority int, o Handler[D, R]) error {
+	return b.Update(func(broker *Broker[D, R]) error {
+		return broker.SubscribeNamed(name, priority, o)
+	})
+}
+
+func (b *SyncBroker[D, R]) InsertBefore(name string, newName string, o Handler[D, R]) error {
+	return b.Update(func(broker *Broker[D, R]) error {
+		return broker.InsertBefore(name, newName, o)
+	})
+}
+
+func (b *SyncBroker[D, R]) InsertAfter(name string, newName string, o Handler[D, R]) error {
+	return b.Update(func(broker *Broker[D, R]) error {
+		return broker.InsertAfter(name, newName, o)
+	})
+}
+
+func (b *SyncBroker[D, R]) Replace(name string, o Handler[D, R]) error {
+	return b.Update(func(broker *Broker[D, R]) error {
+		return broker.Replace(name, o)
+	})
+}
+
+func (b *SyncBroker[D, R]) Remove(name string) error {
+	return b.Update(func(broker *Broker[D, R]) error {
+		return broker.Remove(name)
+	})
+}
+
+func (b *SyncBroker[D, R]) Pipeline() []HandlerRegistration[D, R] {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.broker.Pipeline()
+}
+
+func (b *SyncBroker[D, R]) Fire(q *Query[D, R]) {
+	b.FireContext(context.Background(), q)
+}
+
+func (b *SyncBroker[D, R]) FireContext(ctx context.Context, q *Query[D, R]) {
+	if snapshot := b.snapshot.Load(); snapshot != nil {
+		for _, handler := range snapshot.handlers {
+			if !step(ctx, snapshot.panicPolicy, handler, q) {
+				return
+			}
+		}
+	}
+	checkDone(ctx, q)
+}
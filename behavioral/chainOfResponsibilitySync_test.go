@@ -0,0 +1,87 @@
+package behavioral
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type benchmarkQuery = Query[string, bool]
+
+type noopHandler struct{}
+
+func (noop *noopHandler) Handle(q *benchmarkQuery) {
+	q.Result = true
+}
+
+// lockedBroker is how a list based Broker has to be shared: every Fire take the read lock
+type lockedBroker struct {
+	mutex  sync.RWMutex
+	broker *Broker[string, bool]
+}
+
+func (b *lockedBroker) Subscribe(o Handler[string, bool]) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.broker.Subscribe(o)
+}
+
+func (b *lockedBroker) Unsubscribe(o Handler[string, bool]) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.broker.Unsubscribe(o)
+}
+
+func (b *lockedBroker) Fire(q *benchmarkQuery) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	b.broker.Fire(q)
+}
+
+type benchmarkedBroker interface {
+	Subscribe(o Handler[string, bool])
+	Unsubscribe(o Handler[string, bool])
+	Fire(q *benchmarkQuery)
+}
+
+// benchmarkFire measure Fire from concurrent readers, without subscription change
+// then with a subscription change every 1000 Fire
+func benchmarkFire(b *testing.B, newBroker func() benchmarkedBroker) {
+	for _, writeEvery := range []int{0, 1000} {
+		name := "read-only"
+		if writeEvery > 0 {
+			name = fmt.Sprintf("write-every-%d", writeEvery)
+		}
+		b.Run(name, func(b *testing.B) {
+			broker := newBroker()
+			for i := 0; i < 8; i++ {
+				broker.Subscribe(&noopHandler{})
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				extra := &noopHandler{}
+				for i := 1; pb.Next(); i++ {
+					if writeEvery > 0 && i%writeEvery == 0 {
+						broker.Subscribe(extra)
+						broker.Unsubscribe(extra)
+					}
+					q := benchmarkQuery{Data: "Jane"}
+					broker.Fire(&q)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkBrokerFireRWMutex(b *testing.B) {
+	benchmarkFire(b, func() benchmarkedBroker {
+		return &lockedBroker{broker: NewBroker[string, bool]()}
+	})
+}
+
+func BenchmarkSyncBrokerFire(b *testing.B) {
+	benchmarkFire(b, func() benchmarkedBroker {
+		return NewSyncBroker[string, bool]()
+	})
+}
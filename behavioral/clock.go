@@ -0,0 +1,125 @@
+package behavioral
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock give the time to the patterns depending on it, so that tests can control it
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	Stop() bool
+}
+
+// Sleeper wait for a duration unless the context is done first, SystemClock and ManualClock are sleepers
+type Sleeper interface {
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock is the real clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (SystemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ManualClock only move forward when it is advanced, timers are fired by Advance in their
+// deadline order, from the goroutine calling Advance. It is safe for concurrent use
+type ManualClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	f        func()
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (clock *ManualClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+// AfterFunc register f to be called once the clock is advanced past d, even when d is not positive
+func (clock *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	timer := &manualTimer{clock, clock.now.Add(d), f}
+	clock.timers = append(clock.timers, timer)
+	return timer
+}
+
+// Advance move the clock forward and fire the timers reaching their deadline,
+// including the ones registered by the fired timers
+func (clock *ManualClock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	target := clock.now.Add(d)
+	clock.mutex.Unlock()
+
+	for {
+		clock.mutex.Lock()
+		sort.SliceStable(clock.timers, func(i, j int) bool { return clock.timers[i].deadline.Before(clock.timers[j].deadline) })
+		if len(clock.timers) == 0 || clock.timers[0].deadline.After(target) {
+			clock.now = target
+			clock.mutex.Unlock()
+			return
+		}
+		timer := clock.timers[0]
+		clock.timers = clock.timers[1:]
+		if timer.deadline.After(clock.now) {
+			clock.now = timer.deadline
+		}
+		clock.mutex.Unlock()
+
+		timer.f()
+	}
+}
+
+// Sleep advance the clock instead of waiting
+func (clock *ManualClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	clock.Advance(d)
+	return nil
+}
+
+func (timer *manualTimer) Stop() bool {
+	timer.clock.mutex.Lock()
+	defer timer.clock.mutex.Unlock()
+	for i, pending := range timer.clock.timers {
+		if pending == timer {
+			timer.clock.timers = append(timer.clock.timers[:i], timer.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,9 @@ package behavioral
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Zando74/generic-patterns/creational"
 )
@@ -13,26 +15,143 @@ func (state *State) String(stateToString *map[State]string) string {
 	return (*stateToString)[*state]
 }
 
-type StateMachine struct {
-	CurrentState        State
-	TransitionRules     *map[State][]State
-	stateToString       *map[State]string
-	MaxUnreachableState State
+type Machine[S comparable] struct {
+	CurrentState        S
+	TransitionRules     *map[S][]S
+	EventRules          *map[Event]map[S]S
+	stateToString       *map[S]string
+	MaxUnreachableState S
+	guards              map[Edge[S]][]Guard[S]
+	onEnter             map[S][]Action[S]
+	onExit              map[S][]Action[S]
+	onTransition        map[Edge[S]][]Action[S]
+	parents             map[S]S
+	initialChildren     map[S]S
+	historyKinds        map[S]HistoryKind
+	history             map[S]S
+	regions             map[S][]*region[S]
+	terminalStates      []S
+	transitionLog       TransitionLog[S]
+	version             string
+	clock               Clock
+	timeouts            map[S][]timeout[S]
+	entered             map[S]time.Time
+	timers              map[S][]Timer
+	timeoutDispatch     func(fire func())
+	observers           Observable[Observer]
 }
 
-type InvalidTransitionError struct {
-	Current, Desired *State
-	stateToString    *map[State]string
+type Edge[S comparable] struct {
+	From, To S
 }
 
-func (e *InvalidTransitionError) Error() string {
+// Guard can veto a transition allowed by the transition rules, payload is the one given to GoToWith
+type Guard[S comparable] func(stateMachine *Machine[S], payload interface{}) error
 
-	return strings.ToUpper(fmt.Sprintf("Transition from %s to %s is Not Allowed", (*e.Current).String(e.stateToString), (*e.Desired).String(e.stateToString)))
+// Event name a transition, the event rules give the target state of an event for each source state
+type Event string
+
+type Transition[S comparable] struct {
+	From, To S
+	Event    Event
+	Payload  interface{}
+}
+
+// Action is a hook run during a transition, an error returned by an action
+// roll the machine back to the state it was in before the transition.
+// Actions are run in this order: OnExit of the exited states from the innermost,
+// OnTransition of the edge, then OnEnter of the entered states from the outermost
+// once CurrentState is updated
+type Action[S comparable] func(stateMachine *Machine[S], transition Transition[S]) error
+
+// StateMachine, StateMachineBuilder and their errors keep the historical int based usage
+// (behavioral.State constants + a state to string map) compiling, Machine is the generic machine
+type StateMachine = Machine[State]
+
+type StateMachineBuilder = MachineBuilder[State]
+
+type InvalidTransitionError = TransitionError[State]
+
+type InvalidStateError = StateError[State]
+
+type TransitionError[S comparable] struct {
+	Current, Desired S
+	stateName        func(S) string
+}
+
+func (e *TransitionError[S]) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("Transition from %s to %s is Not Allowed", e.stateName(e.Current), e.stateName(e.Desired)))
+}
+
+type GuardRejectedError[S comparable] struct {
+	Current, Desired S
+	Reason           error
+	stateName        func(S) string
+}
+
+func (e *GuardRejectedError[S]) Error() string {
+
+	return fmt.Sprintf("%s: %s", strings.ToUpper(fmt.Sprintf("Transition from %s to %s is Rejected", e.stateName(e.Current), e.stateName(e.Desired))), e.Reason)
+}
+
+func (e *GuardRejectedError[S]) Unwrap() error {
+	return e.Reason
 }
 
-type InvalidStateError struct{}
+type UnknownEventError[S comparable] struct {
+	Event     Event
+	Current   S
+	stateName func(S) string
+}
+
+func (e *UnknownEventError[S]) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("Event %s is Unknown in State %s", e.Event, e.stateName(e.Current)))
+}
+
+type ActionStage string
+
+const (
+	OnExitStage       ActionStage = "exit"
+	OnTransitionStage ActionStage = "transition"
+	OnEnterStage      ActionStage = "enter"
+)
+
+type ActionError[S comparable] struct {
+	Current, Desired S
+	Stage            ActionStage
+	Err              error
+	stateName        func(S) string
+}
+
+func (e *ActionError[S]) Error() string {
+
+	return fmt.Sprintf("%s: %s", strings.ToUpper(fmt.Sprintf("Transition from %s to %s Failed on %s", e.stateName(e.Current), e.stateName(e.Desired), e.Stage)), e.Err)
+}
+
+func (e *ActionError[S]) Unwrap() error {
+	return e.Err
+}
+
+// StateName return the name of a state from the state to string mapping,
+// falling back on fmt.Stringer or the default formatting of the state value
+func (stateMachine *Machine[S]) StateName(state S) string {
+	if stateMachine.stateToString != nil {
+		if name, ok := (*stateMachine.stateToString)[state]; ok {
+			return name
+		}
+	}
+	if stringer, ok := any(state).(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprint(state)
+}
 
-func (stateMachine *StateMachine) isTransitionAllowed(currentState, desiredState State) bool {
+func (stateMachine *Machine[S]) isTransitionAllowed(currentState, desiredState S) bool {
+	if stateMachine.TransitionRules == nil {
+		return false
+	}
 	for i := 0; i < len((*stateMachine.TransitionRules)[currentState]); i++ {
 		if (*stateMachine.TransitionRules)[currentState][i] == desiredState {
 			return true
@@ -41,54 +160,325 @@ func (stateMachine *StateMachine) isTransitionAllowed(currentState, desiredState
 	return false
 }
 
-func (stateMachine *StateMachine) GoTo(desiredState State) error {
-	if stateMachine.isTransitionAllowed(stateMachine.CurrentState, desiredState) {
-		stateMachine.CurrentState = desiredState
-		return nil
+func (stateMachine *Machine[S]) checkGuards(currentState, desiredState S, payload interface{}) error {
+	for _, guard := range stateMachine.guards[Edge[S]{currentState, desiredState}] {
+		if err := guard(stateMachine, payload); err != nil {
+			return &GuardRejectedError[S]{currentState, desiredState, err, stateMachine.StateName}
+		}
 	}
-	return &InvalidTransitionError{&stateMachine.CurrentState, &desiredState, stateMachine.stateToString}
+	return nil
 }
 
-func (stateMachine *StateMachine) String() string {
-	return stateMachine.CurrentState.String(stateMachine.stateToString)
+func (stateMachine *Machine[S]) runActions(actions []Action[S], stage ActionStage, transition Transition[S]) error {
+	for _, action := range actions {
+		if err := action(stateMachine, transition); err != nil {
+			return &ActionError[S]{transition.From, transition.To, stage, err, stateMachine.StateName}
+		}
+	}
+	return nil
+}
+
+// transition go from the current state to the target of a rule held by source,
+// which is the current state or one of its ancestors
+func (stateMachine *Machine[S]) transition(source, target S, event Event, payload interface{}) error {
+	transition := Transition[S]{From: stateMachine.CurrentState, To: stateMachine.entryLeaf(target), Event: event, Payload: payload}
+	if err := stateMachine.checkGuards(source, target, payload); err != nil {
+		return stateMachine.reject(transition, err)
+	}
+	exited, entered := stateMachine.transitionPaths(source, target, transition.To)
+	for _, state := range exited {
+		if err := stateMachine.runActions(stateMachine.onExit[state], OnExitStage, transition); err != nil {
+			return stateMachine.reject(transition, err)
+		}
+	}
+	if err := stateMachine.runActions(stateMachine.onTransition[Edge[S]{source, target}], OnTransitionStage, transition); err != nil {
+		return stateMachine.reject(transition, err)
+	}
+	remembered := stateMachine.rememberHistory(exited)
+	stateMachine.CurrentState = transition.To
+	for _, state := range entered {
+		if err := stateMachine.runActions(stateMachine.onEnter[state], OnEnterStage, transition); err != nil {
+			stateMachine.CurrentState = transition.From
+			return stateMachine.reject(transition, err)
+		}
+	}
+	if err := stateMachine.record(transition); err != nil {
+		stateMachine.CurrentState = transition.From
+		return stateMachine.reject(transition, err)
+	}
+	stateMachine.commitHistory(remembered)
+	stateMachine.enterRegions(entered)
+	stateMachine.updateTimeouts(exited, entered)
+	stateMachine.publish(TransitionEvent[S]{From: transition.From, To: transition.To, Event: event, Payload: payload})
+	return nil
 }
 
-type StateMachineBuilder struct {
-	creational.FunctionalBuilder[StateMachine]
+// resolveTransition return the state holding the rule allowing the transition to desiredState,
+// rules defined on an ancestor apply to all its children
+func (stateMachine *Machine[S]) resolveTransition(desiredState S) (S, bool) {
+	for _, state := range stateMachine.lineage(stateMachine.CurrentState) {
+		if stateMachine.isTransitionAllowed(state, desiredState) {
+			return state, true
+		}
+	}
+	var none S
+	return none, false
 }
 
-func (builder *StateMachineBuilder) SetCurrentState(initialState State) *StateMachineBuilder {
+func (stateMachine *Machine[S]) GoTo(desiredState S) error {
+	return stateMachine.GoToWith(desiredState, nil)
+}
 
-	builder.AddAction(func(stateMachine *StateMachine) {
+// GoToWith transition to the desired state, the payload is given to the guards of the transition
+func (stateMachine *Machine[S]) GoToWith(desiredState S, payload interface{}) error {
+	transition := Transition[S]{From: stateMachine.CurrentState, To: desiredState, Payload: payload}
+	if bounded, inBounds := stateMachine.inBounds(desiredState); bounded && !inBounds {
+		return stateMachine.reject(transition, &StateError[S]{desiredState, stateMachine.StateName})
+	}
+	if region := stateMachine.regionOf(desiredState); region != nil {
+		return region.GoToWith(desiredState, payload)
+	}
+	source, ok := stateMachine.resolveTransition(desiredState)
+	if !ok {
+		return stateMachine.reject(transition, &TransitionError[S]{stateMachine.CurrentState, desiredState, stateMachine.StateName})
+	}
+	return stateMachine.transition(source, desiredState, "", payload)
+}
+
+// resolveEvent return the state holding the event rule and its target, searching from the current state up to its ancestors
+func (stateMachine *Machine[S]) resolveEvent(event Event) (S, S, bool) {
+	if stateMachine.EventRules != nil {
+		for _, state := range stateMachine.lineage(stateMachine.CurrentState) {
+			if target, ok := (*stateMachine.EventRules)[event][state]; ok {
+				return state, target, true
+			}
+		}
+	}
+	var none S
+	return none, none, false
+}
+
+// CanFire tell if the event is defined for the current state or an active region, guards are not evaluated
+func (stateMachine *Machine[S]) CanFire(event Event) bool {
+	for _, region := range stateMachine.activeRegions() {
+		if region.CanFire(event) {
+			return true
+		}
+	}
+	_, _, ok := stateMachine.resolveEvent(event)
+	return ok
+}
+
+// Fire transition to the target state of the event for the current state, the payload is given to guards and hooks.
+// The event is first offered to the active regions, the machine itself only handle it when no region can
+func (stateMachine *Machine[S]) Fire(event Event, payload interface{}) error {
+	if handled, err := stateMachine.fireRegions(event, payload); handled {
+		return err
+	}
+	source, target, ok := stateMachine.resolveEvent(event)
+	transition := Transition[S]{From: stateMachine.CurrentState, To: target, Event: event, Payload: payload}
+	if !ok {
+		return stateMachine.reject(transition, &UnknownEventError[S]{event, stateMachine.CurrentState, stateMachine.StateName})
+	}
+	if bounded, inBounds := stateMachine.inBounds(target); bounded && !inBounds {
+		return stateMachine.reject(transition, &StateError[S]{target, stateMachine.StateName})
+	}
+	return stateMachine.transition(source, target, event, payload)
+}
+
+func (stateMachine *Machine[S]) String() string {
+	name := stateMachine.StateName(stateMachine.CurrentState)
+	if regions := stateMachine.activeRegions(); len(regions) > 0 {
+		names := make([]string, len(regions))
+		for i, region := range regions {
+			names[i] = region.String()
+		}
+		name += " [" + strings.Join(names, ", ") + "]"
+	}
+	return name
+}
+
+// Rule is a transition defined by the transition rules, by the event rules when Event is set
+// or by a timeout when After is set
+type Rule[S comparable] struct {
+	From, To S
+	Event    Event
+	After    time.Duration
+}
+
+// States return every state known by the machine definition and the current state, sorted by name
+func (stateMachine *Machine[S]) States() []S {
+	states := stateMachine.definedStates()
+	if !contains(states, stateMachine.CurrentState) {
+		states = append(states, stateMachine.CurrentState)
+		stateMachine.sortStates(states)
+	}
+	return states
+}
+
+// definedStates return the states referenced by the definition, sorted by name
+func (stateMachine *Machine[S]) definedStates() []S {
+	states := []S{}
+	add := func(state S) {
+		if !contains(states, state) {
+			states = append(states, state)
+		}
+	}
+	if stateMachine.stateToString != nil {
+		for state := range *stateMachine.stateToString {
+			add(state)
+		}
+	}
+	for _, rule := range stateMachine.Rules() {
+		add(rule.From)
+		add(rule.To)
+	}
+	for child, parent := range stateMachine.parents {
+		add(child)
+		add(parent)
+	}
+	for parent, child := range stateMachine.initialChildren {
+		add(parent)
+		add(child)
+	}
+	stateMachine.sortStates(states)
+	return states
+}
+
+// Rules return the transition rules followed by the event rules and the timeouts, sorted by name
+func (stateMachine *Machine[S]) Rules() []Rule[S] {
+	rules := []Rule[S]{}
+	if stateMachine.TransitionRules != nil {
+		sources := []S{}
+		for from := range *stateMachine.TransitionRules {
+			sources = append(sources, from)
+		}
+		stateMachine.sortStates(sources)
+		for _, from := range sources {
+			for _, to := range (*stateMachine.TransitionRules)[from] {
+				rules = append(rules, Rule[S]{From: from, To: to})
+			}
+		}
+	}
+	if stateMachine.EventRules != nil {
+		events := []Event{}
+		for event := range *stateMachine.EventRules {
+			events = append(events, event)
+		}
+		sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+		for _, event := range events {
+			sources := []S{}
+			for from := range (*stateMachine.EventRules)[event] {
+				sources = append(sources, from)
+			}
+			stateMachine.sortStates(sources)
+			for _, from := range sources {
+				rules = append(rules, Rule[S]{From: from, To: (*stateMachine.EventRules)[event][from], Event: event})
+			}
+		}
+	}
+	return append(rules, stateMachine.timeoutRules()...)
+}
+
+// IsGuarded tell if guards are registered on the transition
+func (stateMachine *Machine[S]) IsGuarded(from, to S) bool {
+	return len(stateMachine.guards[Edge[S]{from, to}]) > 0
+}
+
+func (stateMachine *Machine[S]) sortStates(states []S) {
+	sort.SliceStable(states, func(i, j int) bool {
+		return stateMachine.StateName(states[i]) < stateMachine.StateName(states[j])
+	})
+}
+
+type MachineBuilder[S comparable] struct {
+	creational.FunctionalBuilder[Machine[S]]
+}
+
+func (builder *MachineBuilder[S]) SetCurrentState(initialState S) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
 		stateMachine.CurrentState = initialState
 	})
 
 	return builder
 }
 
-func (builder *StateMachineBuilder) SetTransitionRules(transitionRules *map[State][]State) *StateMachineBuilder {
+func (builder *MachineBuilder[S]) SetTransitionRules(transitionRules *map[S][]S) *MachineBuilder[S] {
 
-	builder.AddAction(func(stateMachine *StateMachine) {
+	builder.AddAction(func(stateMachine *Machine[S]) {
 		stateMachine.TransitionRules = transitionRules
 	})
 
 	return builder
 }
 
-func (builder *StateMachineBuilder) SetStateToString(stateToString *map[State]string) *StateMachineBuilder {
+func (builder *MachineBuilder[S]) SetEventRules(eventRules *map[Event]map[S]S) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		stateMachine.EventRules = eventRules
+	})
 
-	builder.AddAction(func(stateMachine *StateMachine) {
+	return builder
+}
+
+func (builder *MachineBuilder[S]) SetStateToString(stateToString *map[S]string) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
 		stateMachine.stateToString = stateToString
 	})
 
 	return builder
 }
 
-func (builder *StateMachineBuilder) SetMaxUnreachableState(MaxUnreachableState State) *StateMachineBuilder {
+func (builder *MachineBuilder[S]) SetMaxUnreachableState(MaxUnreachableState S) *MachineBuilder[S] {
 
-	builder.AddAction(func(stateMachine *StateMachine) {
+	builder.AddAction(func(stateMachine *Machine[S]) {
 		stateMachine.MaxUnreachableState = MaxUnreachableState
 	})
 
 	return builder
 }
+
+func (builder *MachineBuilder[S]) AddGuard(from, to S, guard Guard[S]) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		appendToMap(&stateMachine.guards, Edge[S]{from, to}, guard)
+	})
+
+	return builder
+}
+
+func (builder *MachineBuilder[S]) OnEnter(state S, action Action[S]) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		appendToMap(&stateMachine.onEnter, state, action)
+	})
+
+	return builder
+}
+
+func (builder *MachineBuilder[S]) OnExit(state S, action Action[S]) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		appendToMap(&stateMachine.onExit, state, action)
+	})
+
+	return builder
+}
+
+func (builder *MachineBuilder[S]) OnTransition(from, to S, action Action[S]) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		appendToMap(&stateMachine.onTransition, Edge[S]{from, to}, action)
+	})
+
+	return builder
+}
+
+func appendToMap[K comparable, V interface{}](m *map[K][]V, key K, value V) {
+	if *m == nil {
+		*m = make(map[K][]V)
+	}
+	(*m)[key] = append((*m)[key], value)
+}
@@ -0,0 +1,156 @@
+package behavioral
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// StateMachineActor run a StateMachine in its own goroutine, events are queued in a bounded mailbox
+// and handled one at a time: guards, hooks, observers and fired timeouts all run from the actor goroutine.
+// The machine must not be used directly once it is given to the actor
+type StateMachineActor[S comparable] struct {
+	stateMachine *Machine[S]
+	mailbox      chan actorMessage[S]
+	mutex        sync.RWMutex // Held to send to the mailbox, exclusively to close it
+	stopped      bool
+	stateMutex   sync.RWMutex
+	current      S
+	done         chan struct{}
+}
+
+type actorMessage[S comparable] struct {
+	event   Event
+	payload interface{}
+	fire    func() // A fired timeout instead of an event
+	reply   chan actorReply[S]
+}
+
+type actorReply[S comparable] struct {
+	state S
+	err   error
+}
+
+type MailboxFullError struct {
+	Size int
+}
+
+func (e *MailboxFullError) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("Mailbox of %d Events is Full", e.Size))
+}
+
+type ActorStoppedError struct{}
+
+func (e *ActorStoppedError) Error() string {
+
+	return strings.ToUpper("State Machine Actor is Stopped")
+}
+
+// NewStateMachineActor start the actor goroutine, mailboxSize is the number of events waiting to be handled
+func NewStateMachineActor[S comparable](stateMachine *Machine[S], mailboxSize int) *StateMachineActor[S] {
+	actor := &StateMachineActor[S]{
+		stateMachine: stateMachine,
+		mailbox:      make(chan actorMessage[S], mailboxSize),
+		current:      stateMachine.CurrentState,
+		done:         make(chan struct{}),
+	}
+	stateMachine.setTimeoutDispatch(func(fire func()) {
+		_ = actor.enqueue(context.Background(), actorMessage[S]{fire: fire})
+	})
+	go actor.run()
+	return actor
+}
+
+func (actor *StateMachineActor[S]) run() {
+	defer close(actor.done)
+	for message := range actor.mailbox {
+		var err error
+		if message.fire != nil {
+			message.fire()
+		} else {
+			err = actor.stateMachine.Fire(message.event, message.payload)
+		}
+
+		actor.stateMutex.Lock()
+		actor.current = actor.stateMachine.CurrentState
+		actor.stateMutex.Unlock()
+
+		if message.reply != nil {
+			message.reply <- actorReply[S]{actor.stateMachine.CurrentState, err}
+		}
+	}
+	actor.stateMachine.StopTimeouts()
+}
+
+// enqueue wait for room in the mailbox until the context is done
+func (actor *StateMachineActor[S]) enqueue(ctx context.Context, message actorMessage[S]) error {
+	actor.mutex.RLock()
+	defer actor.mutex.RUnlock()
+	if actor.stopped {
+		return &ActorStoppedError{}
+	}
+	select {
+	case actor.mailbox <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Send queue the event without waiting for it to be handled, a MailboxFullError is returned
+// instead of blocking when the mailbox is full. The error of the transition is only given to the observers
+func (actor *StateMachineActor[S]) Send(event Event, payload interface{}) error {
+	actor.mutex.RLock()
+	defer actor.mutex.RUnlock()
+	if actor.stopped {
+		return &ActorStoppedError{}
+	}
+	select {
+	case actor.mailbox <- actorMessage[S]{event: event, payload: payload}:
+		return nil
+	default:
+		return &MailboxFullError{cap(actor.mailbox)}
+	}
+}
+
+// Ask queue the event and wait for it to be handled, the state of the machine after the event is returned
+// with the error of the transition. Waiting for room in the mailbox or for the result end with the context
+func (actor *StateMachineActor[S]) Ask(ctx context.Context, event Event, payload interface{}) (S, error) {
+	reply := make(chan actorReply[S], 1)
+	if err := actor.enqueue(ctx, actorMessage[S]{event: event, payload: payload, reply: reply}); err != nil {
+		return actor.Current(), err
+	}
+	select {
+	case result := <-reply:
+		return result.state, result.err
+	case <-ctx.Done():
+		return actor.Current(), ctx.Err()
+	}
+}
+
+// Current return the state of the machine after the last handled event
+func (actor *StateMachineActor[S]) Current() S {
+	actor.stateMutex.RLock()
+	defer actor.stateMutex.RUnlock()
+	return actor.current
+}
+
+// Stop refuse new events, handle the events already in the mailbox then cancel the timeouts of the machine.
+// It wait for the actor goroutine to end until the context is done, the actor still drain its mailbox afterwards
+func (actor *StateMachineActor[S]) Stop(ctx context.Context) error {
+	actor.mutex.Lock()
+	if !actor.stopped {
+		actor.stopped = true
+		close(actor.mailbox)
+	}
+	actor.mutex.Unlock()
+
+	select {
+	case <-actor.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,518 @@
+package behavioral
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateMachineDefinition is a serializable machine definition where states are referenced by name.
+// Guards, hooks, histories and regions are code and are not part of a definition
+type StateMachineDefinition struct {
+	Initial         string                 `json:"initial"`
+	States          []string               `json:"states"`
+	Terminal        []string               `json:"terminal,omitempty"`
+	Parents         map[string]string      `json:"parents,omitempty"`         // Child name to parent name
+	InitialChildren map[string]string      `json:"initialChildren,omitempty"` // Parent name to initial child name
+	Transitions     []TransitionDefinition `json:"transitions"`
+	positions       map[string]int
+}
+
+// TransitionDefinition is a transition rule, an event rule when Event is set
+// or a timeout when After is set to a duration ("48h")
+type TransitionDefinition struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Event string `json:"event,omitempty"`
+	After string `json:"after,omitempty"`
+}
+
+// DefinitionError point at the offending key of a definition document, Line is 0 when unknown
+type DefinitionError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e *DefinitionError) Error() string {
+	location := e.Path
+	if e.Line > 0 && e.Path == "" {
+		location = fmt.Sprintf("line %d", e.Line)
+	} else if e.Line > 0 {
+		location = fmt.Sprintf("%s (line %d)", e.Path, e.Line)
+	}
+	if location == "" {
+		return "INVALID STATE MACHINE DEFINITION: " + e.Message
+	}
+	return fmt.Sprintf("INVALID STATE MACHINE DEFINITION at %s: %s", location, e.Message)
+}
+
+// DefinitionDecoder decode a document into generic values (map[string]interface{}, []interface{}, string, ...)
+// and the line of each key path ("transitions[2].to"), positions can be nil when they are not tracked
+type DefinitionDecoder func(data []byte) (document interface{}, positions map[string]int, err error)
+
+// StateCodec convert states from and to the names used in definitions
+type StateCodec[S comparable] interface {
+	FormatState(state S) string
+	ParseState(name string) (S, error)
+}
+
+// StringStateCodec is the codec of string based states, the state is its own name
+type StringStateCodec[S ~string] struct{}
+
+func (codec StringStateCodec[S]) FormatState(state S) string {
+	return string(state)
+}
+
+func (codec StringStateCodec[S]) ParseState(name string) (S, error) {
+	return S(name), nil
+}
+
+type namedStateCodec[S comparable] struct {
+	stateToString *map[S]string
+}
+
+// NewNamedStateCodec return a codec using a state to string mapping, as used by the int based machines
+func NewNamedStateCodec[S comparable](stateToString *map[S]string) StateCodec[S] {
+	return &namedStateCodec[S]{stateToString}
+}
+
+func (codec *namedStateCodec[S]) FormatState(state S) string {
+	if name, ok := (*codec.stateToString)[state]; ok {
+		return name
+	}
+	return fmt.Sprint(state)
+}
+
+func (codec *namedStateCodec[S]) ParseState(name string) (S, error) {
+	for state, stateName := range *codec.stateToString {
+		if stateName == name {
+			return state, nil
+		}
+	}
+	var none S
+	return none, fmt.Errorf("no state is named %q", name)
+}
+
+// LoadStateMachineDefinition decode and check a definition document
+func LoadStateMachineDefinition(data []byte, decoder DefinitionDecoder) (*StateMachineDefinition, error) {
+	document, positions, err := decoder(data)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := definitionFromDocument(document, positions)
+	if err != nil {
+		return nil, err
+	}
+	return definition, definition.check()
+}
+
+// NewStateMachineBuilderFromDefinition return a builder configured with the definition, the names of the
+// definition become the state to string mapping. Guards and hooks can then be added on the builder
+func NewStateMachineBuilderFromDefinition[S comparable](definition *StateMachineDefinition, codec StateCodec[S]) (*MachineBuilder[S], error) {
+	if err := definition.check(); err != nil {
+		return nil, err
+	}
+	states := map[string]S{}
+	stateToString := map[S]string{}
+	for i, name := range definition.States {
+		state, err := codec.ParseState(name)
+		if err != nil {
+			return nil, definition.errorAt(fmt.Sprintf("states[%d]", i), err.Error())
+		}
+		states[name] = state
+		stateToString[state] = name
+	}
+
+	transitionRules := map[S][]S{}
+	eventRules := map[Event]map[S]S{}
+	timeouts := []Rule[S]{}
+	for _, transition := range definition.Transitions {
+		from, to := states[transition.From], states[transition.To]
+		if transition.After != "" {
+			after, _ := time.ParseDuration(transition.After)
+			timeouts = append(timeouts, Rule[S]{From: from, To: to, After: after})
+			continue
+		}
+		if transition.Event == "" {
+			transitionRules[from] = append(transitionRules[from], to)
+			continue
+		}
+		if eventRules[Event(transition.Event)] == nil {
+			eventRules[Event(transition.Event)] = map[S]S{}
+		}
+		eventRules[Event(transition.Event)][from] = to
+	}
+
+	terminalStates := []S{}
+	for _, name := range definition.Terminal {
+		terminalStates = append(terminalStates, states[name])
+	}
+
+	builder := (&MachineBuilder[S]{}).
+		SetCurrentState(states[definition.Initial]).
+		SetStateToString(&stateToString).
+		SetTransitionRules(&transitionRules).
+		SetEventRules(&eventRules).
+		SetTerminalStates(terminalStates...)
+	for _, child := range sortedKeys(definition.Parents) {
+		builder.SetParent(states[child], states[definition.Parents[child]])
+	}
+	for _, parent := range sortedKeys(definition.InitialChildren) {
+		builder.SetInitialChild(states[parent], states[definition.InitialChildren[parent]])
+	}
+	for _, timeout := range timeouts {
+		builder.AddTimeout(timeout.From, timeout.After, timeout.To)
+	}
+	return builder, nil
+}
+
+// NewStateMachineDefinition dump the definition of a machine, its current state become the initial state
+func NewStateMachineDefinition[S comparable](stateMachine *Machine[S], codec StateCodec[S]) *StateMachineDefinition {
+	definition := &StateMachineDefinition{
+		Initial:     codec.FormatState(stateMachine.CurrentState),
+		States:      []string{},
+		Transitions: []TransitionDefinition{},
+	}
+	for _, state := range stateMachine.States() {
+		definition.States = append(definition.States, codec.FormatState(state))
+		if stateMachine.IsTerminal(state) {
+			definition.Terminal = append(definition.Terminal, codec.FormatState(state))
+		}
+	}
+	for child, parent := range stateMachine.parents {
+		if definition.Parents == nil {
+			definition.Parents = map[string]string{}
+		}
+		definition.Parents[codec.FormatState(child)] = codec.FormatState(parent)
+	}
+	for parent, child := range stateMachine.initialChildren {
+		if definition.InitialChildren == nil {
+			definition.InitialChildren = map[string]string{}
+		}
+		definition.InitialChildren[codec.FormatState(parent)] = codec.FormatState(child)
+	}
+	for _, rule := range stateMachine.Rules() {
+		transition := TransitionDefinition{
+			From:  codec.FormatState(rule.From),
+			To:    codec.FormatState(rule.To),
+			Event: string(rule.Event),
+		}
+		if rule.After != 0 {
+			transition.After = rule.After.String()
+		}
+		definition.Transitions = append(definition.Transitions, transition)
+	}
+	return definition
+}
+
+// JSON encode the definition in the format read by JSONDefinitionDecoder
+func (definition *StateMachineDefinition) JSON() ([]byte, error) {
+	return json.MarshalIndent(definition, "", "  ")
+}
+
+// check that every state referenced by the definition is declared in its states
+func (definition *StateMachineDefinition) check() error {
+	declared := map[string]bool{}
+	for i, name := range definition.States {
+		if declared[name] {
+			return definition.errorAt(fmt.Sprintf("states[%d]", i), fmt.Sprintf("state %q is declared twice", name))
+		}
+		declared[name] = true
+	}
+
+	checkDeclared := func(path, name string) error {
+		if !declared[name] {
+			return definition.errorAt(path, fmt.Sprintf("state %q is not declared in states", name))
+		}
+		return nil
+	}
+
+	if definition.Initial == "" {
+		return definition.errorAt("initial", "initial state is required")
+	}
+	if err := checkDeclared("initial", definition.Initial); err != nil {
+		return err
+	}
+	for i, name := range definition.Terminal {
+		if err := checkDeclared(fmt.Sprintf("terminal[%d]", i), name); err != nil {
+			return err
+		}
+	}
+	for _, child := range sortedKeys(definition.Parents) {
+		if err := checkDeclared("parents."+child, child); err != nil {
+			return err
+		}
+		if err := checkDeclared("parents."+child, definition.Parents[child]); err != nil {
+			return err
+		}
+	}
+	for _, parent := range sortedKeys(definition.InitialChildren) {
+		if err := checkDeclared("initialChildren."+parent, parent); err != nil {
+			return err
+		}
+		if err := checkDeclared("initialChildren."+parent, definition.InitialChildren[parent]); err != nil {
+			return err
+		}
+	}
+	events := map[TransitionDefinition]int{}
+	for i, transition := range definition.Transitions {
+		if err := checkDeclared(fmt.Sprintf("transitions[%d].from", i), transition.From); err != nil {
+			return err
+		}
+		if err := checkDeclared(fmt.Sprintf("transitions[%d].to", i), transition.To); err != nil {
+			return err
+		}
+		if transition.After != "" {
+			if transition.Event != "" {
+				return definition.errorAt(fmt.Sprintf("transitions[%d].after", i), "a transition can't have both an event and a timeout")
+			}
+			if after, err := time.ParseDuration(transition.After); err != nil || after <= 0 {
+				return definition.errorAt(fmt.Sprintf("transitions[%d].after", i), fmt.Sprintf("a positive duration is expected, got %q", transition.After))
+			}
+		}
+		if transition.Event == "" {
+			continue
+		}
+		key := TransitionDefinition{From: transition.From, Event: transition.Event}
+		if previous, ok := events[key]; ok {
+			return definition.errorAt(fmt.Sprintf("transitions[%d].event", i), fmt.Sprintf("event %q from %q is already defined by transitions[%d]", transition.Event, transition.From, previous))
+		}
+		events[key] = i
+	}
+	return nil
+}
+
+func (definition *StateMachineDefinition) errorAt(path, message string) *DefinitionError {
+	return &DefinitionError{Path: path, Line: definition.positions[path], Message: message}
+}
+
+// definitionFromDocument convert the generic values of a decoded document into a definition
+func definitionFromDocument(document interface{}, positions map[string]int) (*StateMachineDefinition, error) {
+	definition := &StateMachineDefinition{positions: positions}
+	root, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, definition.errorAt("", "a mapping is expected at the root of the document")
+	}
+
+	var err error
+	for _, key := range sortedKeys(root) {
+		value := root[key]
+		switch key {
+		case "initial":
+			definition.Initial, err = definition.asString(key, value)
+		case "states":
+			definition.States, err = definition.asStringList(key, value)
+		case "terminal":
+			definition.Terminal, err = definition.asStringList(key, value)
+		case "parents":
+			definition.Parents, err = definition.asStringMap(key, value)
+		case "initialChildren":
+			definition.InitialChildren, err = definition.asStringMap(key, value)
+		case "transitions":
+			definition.Transitions, err = definition.asTransitions(key, value)
+		default:
+			err = definition.errorAt(key, fmt.Sprintf("unknown key %q", key))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return definition, nil
+}
+
+func (definition *StateMachineDefinition) asString(path string, value interface{}) (string, error) {
+	text, ok := value.(string)
+	if !ok {
+		return "", definition.errorAt(path, fmt.Sprintf("a string is expected, got %s", describeValue(value)))
+	}
+	return text, nil
+}
+
+func (definition *StateMachineDefinition) asStringList(path string, value interface{}) ([]string, error) {
+	if value == nil {
+		return []string{}, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, definition.errorAt(path, fmt.Sprintf("a list is expected, got %s", describeValue(value)))
+	}
+	list := make([]string, len(items))
+	for i, item := range items {
+		text, err := definition.asString(fmt.Sprintf("%s[%d]", path, i), item)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = text
+	}
+	return list, nil
+}
+
+func (definition *StateMachineDefinition) asStringMap(path string, value interface{}) (map[string]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, definition.errorAt(path, fmt.Sprintf("a mapping is expected, got %s", describeValue(value)))
+	}
+	mapping := map[string]string{}
+	for key, entry := range entries {
+		text, err := definition.asString(path+"."+key, entry)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = text
+	}
+	return mapping, nil
+}
+
+func (definition *StateMachineDefinition) asTransitions(path string, value interface{}) ([]TransitionDefinition, error) {
+	if value == nil {
+		return []TransitionDefinition{}, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, definition.errorAt(path, fmt.Sprintf("a list is expected, got %s", describeValue(value)))
+	}
+	transitions := make([]TransitionDefinition, len(items))
+	for i, item := range items {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		entries, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, definition.errorAt(itemPath, fmt.Sprintf("a mapping with from, to and event or after is expected, got %s", describeValue(item)))
+		}
+		for _, key := range sortedKeys(entries) {
+			var err error
+			switch key {
+			case "from":
+				transitions[i].From, err = definition.asString(itemPath+".from", entries[key])
+			case "to":
+				transitions[i].To, err = definition.asString(itemPath+".to", entries[key])
+			case "event":
+				transitions[i].Event, err = definition.asString(itemPath+".event", entries[key])
+			case "after":
+				transitions[i].After, err = definition.asString(itemPath+".after", entries[key])
+			default:
+				err = definition.errorAt(itemPath+"."+key, fmt.Sprintf("unknown key %q", key))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if transitions[i].From == "" || transitions[i].To == "" {
+			return nil, definition.errorAt(itemPath, "from and to are required")
+		}
+	}
+	return transitions, nil
+}
+
+func describeValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nothing"
+	case map[string]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a list"
+	}
+	return fmt.Sprintf("%T %v", value, value)
+}
+
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// JSONDefinitionDecoder decode a JSON document, tracking the line of each key
+func JSONDefinitionDecoder(data []byte) (interface{}, map[string]int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	positions := map[string]int{}
+	document, err := decodeJSONValue(decoder, data, "", positions)
+	if err == nil {
+		if _, err = decoder.Token(); err != io.EOF {
+			err = &DefinitionError{Line: lineAt(data, int(decoder.InputOffset())), Message: "unexpected data after the document"}
+		} else {
+			err = nil
+		}
+	}
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		err = &DefinitionError{Line: lineAt(data, int(syntaxError.Offset)), Message: syntaxError.Error()}
+	}
+	return document, positions, err
+}
+
+func decodeJSONValue(decoder *json.Decoder, data []byte, path string, positions map[string]int) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, &DefinitionError{Path: path, Line: lineAt(data, len(data)), Message: "unexpected end of the document"}
+		}
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		mapping := map[string]interface{}{}
+		for decoder.More() {
+			line := lineAt(data, nextTokenOffset(data, int(decoder.InputOffset())))
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if _, ok := mapping[key]; ok {
+				return nil, &DefinitionError{Path: keyPath, Line: line, Message: fmt.Sprintf("duplicate key %q", key)}
+			}
+			positions[keyPath] = line
+			if mapping[key], err = decodeJSONValue(decoder, data, keyPath, positions); err != nil {
+				return nil, err
+			}
+		}
+		_, err = decoder.Token()
+		return mapping, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for decoder.More() {
+			itemPath := fmt.Sprintf("%s[%d]", path, len(list))
+			positions[itemPath] = lineAt(data, nextTokenOffset(data, int(decoder.InputOffset())))
+			item, err := decodeJSONValue(decoder, data, itemPath, positions)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		_, err = decoder.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+// nextTokenOffset skip the separators following the previous token
+func nextTokenOffset(data []byte, offset int) int {
+	for offset < len(data) && strings.ContainsRune(" \t\r\n,:", rune(data[offset])) {
+		offset++
+	}
+	return offset
+}
+
+func lineAt(data []byte, offset int) int {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
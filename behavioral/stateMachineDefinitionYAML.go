@@ -0,0 +1,333 @@
+package behavioral
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a meaningful line of a YAML document, without its indentation and comment
+type yamlLine struct {
+	number  int
+	indent  int
+	content string
+}
+
+type yamlParser struct {
+	lines     []yamlLine
+	index     int
+	positions map[string]int
+}
+
+// YAMLDefinitionDecoder decode the YAML subset needed by definitions, tracking the line of each key:
+// block mappings and sequences, flow sequences and mappings of scalars, plain and quoted scalars, comments.
+// Anchors, tags, multi-line scalars and multiple documents are not supported, use a dedicated library
+// wrapped in a DefinitionDecoder for them
+func YAMLDefinitionDecoder(data []byte) (interface{}, map[string]int, error) {
+	parser := &yamlParser{positions: map[string]int{}}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		content := strings.TrimRight(stripYAMLComment(raw), " \t")
+		trimmed := strings.TrimLeft(content, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, nil, &DefinitionError{Line: i + 1, Message: "tabs are not allowed for indentation"}
+		}
+		parser.lines = append(parser.lines, yamlLine{i + 1, len(content) - len(trimmed), trimmed})
+	}
+	if len(parser.lines) == 0 {
+		return nil, parser.positions, nil
+	}
+
+	document, err := parser.parseBlock("", parser.lines[0].indent)
+	if err == nil && parser.index < len(parser.lines) {
+		err = &DefinitionError{Line: parser.lines[parser.index].number, Message: "unexpected indentation"}
+	}
+	return document, parser.positions, err
+}
+
+func (parser *yamlParser) parseBlock(path string, indent int) (interface{}, error) {
+	if isYAMLSequenceItem(parser.lines[parser.index].content) {
+		return parser.parseSequence(path, indent)
+	}
+	return parser.parseMapping(path, indent)
+}
+
+func (parser *yamlParser) parseMapping(path string, indent int) (interface{}, error) {
+	mapping := map[string]interface{}{}
+	for parser.index < len(parser.lines) {
+		line := parser.lines[parser.index]
+		if line.indent < indent || (line.indent == indent && isYAMLSequenceItem(line.content)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, &DefinitionError{Line: line.number, Message: "unexpected indentation"}
+		}
+
+		key, rest, ok := splitYAMLKey(line.content)
+		if !ok {
+			return nil, &DefinitionError{Path: path, Line: line.number, Message: fmt.Sprintf("a key is expected, got %q", line.content)}
+		}
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		if _, ok := mapping[key]; ok {
+			return nil, &DefinitionError{Path: keyPath, Line: line.number, Message: fmt.Sprintf("duplicate key %q", key)}
+		}
+		parser.positions[keyPath] = line.number
+		parser.index++
+
+		value, err := parser.parseValue(keyPath, indent, rest, line.number, true)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	return mapping, nil
+}
+
+func (parser *yamlParser) parseSequence(path string, indent int) (interface{}, error) {
+	list := []interface{}{}
+	for parser.index < len(parser.lines) {
+		line := parser.lines[parser.index]
+		if line.indent < indent || !isYAMLSequenceItem(line.content) {
+			break
+		}
+		if line.indent > indent {
+			return nil, &DefinitionError{Line: line.number, Message: "unexpected indentation"}
+		}
+
+		itemPath := fmt.Sprintf("%s[%d]", path, len(list))
+		parser.positions[itemPath] = line.number
+		rest := strings.TrimLeft(strings.TrimPrefix(line.content, "-"), " ")
+
+		// "- key: value" start a mapping indented at the position of its first key
+		if _, _, ok := splitYAMLKey(rest); ok && !strings.HasPrefix(rest, "[") && !strings.HasPrefix(rest, "{") {
+			itemIndent := line.indent + len(line.content) - len(rest)
+			parser.lines[parser.index] = yamlLine{line.number, itemIndent, rest}
+			item, err := parser.parseMapping(itemPath, itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			continue
+		}
+
+		parser.index++
+		item, err := parser.parseValue(itemPath, indent, rest, line.number, false)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// parseValue parse the inline value of a key or an item, or the nested block following it when there is none.
+// The items of a sequence value can be at the same indentation as the key
+func (parser *yamlParser) parseValue(path string, indent int, inline string, lineNumber int, isKey bool) (interface{}, error) {
+	if inline != "" {
+		return parser.parseFlow(path, inline, lineNumber)
+	}
+	if parser.index < len(parser.lines) {
+		next := parser.lines[parser.index]
+		if next.indent > indent || (isKey && next.indent == indent && isYAMLSequenceItem(next.content)) {
+			return parser.parseBlock(path, next.indent)
+		}
+	}
+	return nil, nil
+}
+
+// parseFlow parse a scalar or a flow collection of scalars
+func (parser *yamlParser) parseFlow(path, text string, lineNumber int) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, &DefinitionError{Path: path, Line: lineNumber, Message: "unterminated flow sequence"}
+		}
+		list := []interface{}{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			itemPath := fmt.Sprintf("%s[%d]", path, len(list))
+			parser.positions[itemPath] = lineNumber
+			value, err := parseYAMLScalar(itemPath, item, lineNumber)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case strings.HasPrefix(text, "{"):
+		if !strings.HasSuffix(text, "}") {
+			return nil, &DefinitionError{Path: path, Line: lineNumber, Message: "unterminated flow mapping"}
+		}
+		mapping := map[string]interface{}{}
+		for _, entry := range splitYAMLFlow(text[1 : len(text)-1]) {
+			key, rest, ok := splitYAMLKey(entry)
+			if !ok {
+				return nil, &DefinitionError{Path: path, Line: lineNumber, Message: fmt.Sprintf("a key is expected, got %q", entry)}
+			}
+			parser.positions[path+"."+key] = lineNumber
+			value, err := parseYAMLScalar(path+"."+key, rest, lineNumber)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+		}
+		return mapping, nil
+	}
+	return parseYAMLScalar(path, text, lineNumber)
+}
+
+func parseYAMLScalar(path, text string, lineNumber int) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(text, "\""):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, &DefinitionError{Path: path, Line: lineNumber, Message: fmt.Sprintf("invalid double quoted string %s", text)}
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, &DefinitionError{Path: path, Line: lineNumber, Message: fmt.Sprintf("invalid single quoted string %s", text)}
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text == "" || text == "~" || text == "null":
+		return nil, nil
+	case strings.ContainsAny(text[:1], "[]{}&*!|>%@`"):
+		return nil, &DefinitionError{Path: path, Line: lineNumber, Message: fmt.Sprintf("unsupported YAML syntax %q", text)}
+	}
+	return text, nil
+}
+
+// splitYAMLKey split "key: value" outside of quotes, ok is false when the text is not a key
+func splitYAMLKey(text string) (string, string, bool) {
+	quote := rune(0)
+	for i, char := range text {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == ':' && (i == len(text)-1 || text[i+1] == ' '):
+			key := strings.TrimSpace(text[:i])
+			if unquoted, err := parseYAMLScalar("", key, 0); err == nil && unquoted != nil {
+				key = unquoted.(string)
+			}
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+// splitYAMLFlow split the items of a flow collection on the commas outside of quotes
+func splitYAMLFlow(text string) []string {
+	items := []string{}
+	quote := rune(0)
+	start := 0
+	for i, char := range text {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == ',':
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+func stripYAMLComment(line string) string {
+	quote := rune(0)
+	for i, char := range line {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func isYAMLSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+var yamlPlainScalar = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./-]*$`)
+
+// formatYAMLScalar quote the scalars that would not be read back as the same string
+func formatYAMLScalar(text string) string {
+	switch strings.ToLower(text) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(text)
+	}
+	if yamlPlainScalar.MatchString(text) && strings.TrimSpace(text) == text {
+		return text
+	}
+	return strconv.Quote(text)
+}
+
+// YAML encode the definition in the format read by YAMLDefinitionDecoder
+func (definition *StateMachineDefinition) YAML() []byte {
+	var builder strings.Builder
+	writeList := func(key string, items []string) {
+		if len(items) == 0 {
+			fmt.Fprintf(&builder, "%s: []\n", key)
+			return
+		}
+		fmt.Fprintf(&builder, "%s:\n", key)
+		for _, item := range items {
+			fmt.Fprintf(&builder, "  - %s\n", formatYAMLScalar(item))
+		}
+	}
+	writeMap := func(key string, entries map[string]string) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&builder, "%s:\n", key)
+		for _, name := range sortedKeys(entries) {
+			fmt.Fprintf(&builder, "  %s: %s\n", formatYAMLScalar(name), formatYAMLScalar(entries[name]))
+		}
+	}
+
+	fmt.Fprintf(&builder, "initial: %s\n", formatYAMLScalar(definition.Initial))
+	writeList("states", definition.States)
+	if len(definition.Terminal) > 0 {
+		writeList("terminal", definition.Terminal)
+	}
+	writeMap("parents", definition.Parents)
+	writeMap("initialChildren", definition.InitialChildren)
+	if len(definition.Transitions) == 0 {
+		builder.WriteString("transitions: []\n")
+	} else {
+		builder.WriteString("transitions:\n")
+	}
+	for _, transition := range definition.Transitions {
+		fmt.Fprintf(&builder, "  - from: %s\n", formatYAMLScalar(transition.From))
+		fmt.Fprintf(&builder, "    to: %s\n", formatYAMLScalar(transition.To))
+		if transition.Event != "" {
+			fmt.Fprintf(&builder, "    event: %s\n", formatYAMLScalar(transition.Event))
+		}
+		if transition.After != "" {
+			fmt.Fprintf(&builder, "    after: %s\n", formatYAMLScalar(transition.After))
+		}
+	}
+	return []byte(builder.String())
+}
@@ -0,0 +1,190 @@
+package behavioral
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DOT render the machine definition as a Graphviz digraph. Composite states are clusters,
+// regions are dashed clusters inside their state, the current state is filled
+func (stateMachine *Machine[S]) DOT() string {
+	var builder strings.Builder
+	builder.WriteString("digraph StateMachine {\n")
+	builder.WriteString("\tcompound=true;\n")
+	builder.WriteString("\trankdir=LR;\n")
+	builder.WriteString("\tnode [shape=box, style=rounded];\n")
+	stateMachine.writeDOT(&builder, "\t", true)
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func (stateMachine *Machine[S]) writeDOT(builder *strings.Builder, indent string, active bool) {
+	for _, state := range stateMachine.States() {
+		if _, ok := stateMachine.parents[state]; !ok {
+			stateMachine.writeDOTState(builder, indent, state, active)
+		}
+	}
+	for _, edge := range stateMachine.exportedEdges() {
+		attributes := []string{}
+		if label := stateMachine.edgeLabel(edge); label != "" {
+			attributes = append(attributes, "label="+strconv.Quote(label))
+		}
+		if stateMachine.isComposite(edge.From) {
+			attributes = append(attributes, "ltail="+strconv.Quote("cluster_"+stateMachine.StateName(edge.From)))
+		}
+		if stateMachine.isComposite(edge.To) {
+			attributes = append(attributes, "lhead="+strconv.Quote("cluster_"+stateMachine.StateName(edge.To)))
+		}
+		fmt.Fprintf(builder, "%s%s -> %s", indent, strconv.Quote(stateMachine.StateName(edge.From)), strconv.Quote(stateMachine.StateName(edge.To)))
+		if len(attributes) > 0 {
+			fmt.Fprintf(builder, " [%s]", strings.Join(attributes, ", "))
+		}
+		builder.WriteString(";\n")
+	}
+}
+
+func (stateMachine *Machine[S]) writeDOTState(builder *strings.Builder, indent string, state S, active bool) {
+	name := stateMachine.StateName(state)
+
+	if !stateMachine.isComposite(state) {
+		fmt.Fprintf(builder, "%s%s", indent, strconv.Quote(name))
+		if active && state == stateMachine.CurrentState {
+			builder.WriteString(" [style=\"rounded,filled\", fillcolor=lightblue]")
+		}
+		builder.WriteString(";\n")
+		return
+	}
+
+	// Edges from and to a composite state are drawn between an invisible anchor node and the cluster border
+	fmt.Fprintf(builder, "%ssubgraph %s {\n", indent, strconv.Quote("cluster_"+name))
+	fmt.Fprintf(builder, "%s\tlabel=%s;\n", indent, strconv.Quote(name))
+	if active && stateMachine.IsIn(state) {
+		fmt.Fprintf(builder, "%s\tstyle=\"rounded,filled\";\n%s\tfillcolor=aliceblue;\n", indent, indent)
+	}
+	fmt.Fprintf(builder, "%s\t%s [shape=point, style=invis];\n", indent, strconv.Quote(name))
+	for _, child := range stateMachine.Children(state) {
+		stateMachine.writeDOTState(builder, indent+"\t", child, active)
+	}
+	for i, region := range stateMachine.regions[state] {
+		fmt.Fprintf(builder, "%s\tsubgraph %s {\n", indent, strconv.Quote(fmt.Sprintf("cluster_%s_region_%d", name, i)))
+		fmt.Fprintf(builder, "%s\t\tlabel=\"\";\n%s\t\tstyle=dashed;\n", indent, indent)
+		region.machine.writeDOT(builder, indent+"\t\t", active && stateMachine.IsIn(state))
+		fmt.Fprintf(builder, "%s\t}\n", indent)
+	}
+	fmt.Fprintf(builder, "%s}\n", indent)
+}
+
+// Mermaid render the machine definition as a Mermaid stateDiagram-v2. Composite states are nested,
+// regions are separated by --, the current states are given the current class
+func (stateMachine *Machine[S]) Mermaid() string {
+	var builder strings.Builder
+	ids := map[S]string{}
+	current := []string{}
+	builder.WriteString("stateDiagram-v2\n")
+	stateMachine.writeMermaid(&builder, "\t", ids, &current, true)
+	builder.WriteString("\tclassDef current fill:lightblue,font-weight:bold\n")
+	for _, id := range current {
+		fmt.Fprintf(&builder, "\tclass %s current\n", id)
+	}
+	return builder.String()
+}
+
+func (stateMachine *Machine[S]) writeMermaid(builder *strings.Builder, indent string, ids map[S]string, current *[]string, active bool) {
+	for _, state := range stateMachine.States() {
+		if _, ok := stateMachine.parents[state]; !ok {
+			stateMachine.writeMermaidState(builder, indent, state, ids, current, active)
+		}
+	}
+	for _, edge := range stateMachine.exportedEdges() {
+		fmt.Fprintf(builder, "%s%s --> %s", indent, mermaidID(ids, edge.From), mermaidID(ids, edge.To))
+		if label := stateMachine.edgeLabel(edge); label != "" {
+			fmt.Fprintf(builder, " : %s", label)
+		}
+		builder.WriteString("\n")
+	}
+}
+
+func (stateMachine *Machine[S]) writeMermaidState(builder *strings.Builder, indent string, state S, ids map[S]string, current *[]string, active bool) {
+	id := mermaidID(ids, state)
+	fmt.Fprintf(builder, "%sstate %s as %s\n", indent, strconv.Quote(stateMachine.StateName(state)), id)
+
+	if !stateMachine.isComposite(state) {
+		if active && state == stateMachine.CurrentState {
+			*current = append(*current, id)
+		}
+		return
+	}
+
+	fmt.Fprintf(builder, "%sstate %s {\n", indent, id)
+	children := stateMachine.Children(state)
+	if child, ok := stateMachine.initialChildren[state]; ok {
+		fmt.Fprintf(builder, "%s\t[*] --> %s\n", indent, mermaidID(ids, child))
+	}
+	for _, child := range children {
+		stateMachine.writeMermaidState(builder, indent+"\t", child, ids, current, active)
+	}
+	for i, region := range stateMachine.regions[state] {
+		if i > 0 || len(children) > 0 {
+			fmt.Fprintf(builder, "%s\t--\n", indent)
+		}
+		fmt.Fprintf(builder, "%s\t[*] --> %s\n", indent, mermaidID(ids, region.initial))
+		region.machine.writeMermaid(builder, indent+"\t", ids, current, active && stateMachine.IsIn(state))
+	}
+	fmt.Fprintf(builder, "%s}\n", indent)
+}
+
+func mermaidID[S comparable](ids map[S]string, state S) string {
+	if id, ok := ids[state]; ok {
+		return id
+	}
+	ids[state] = fmt.Sprintf("s%d", len(ids))
+	return ids[state]
+}
+
+func (stateMachine *Machine[S]) isComposite(state S) bool {
+	if len(stateMachine.regions[state]) > 0 {
+		return true
+	}
+	for _, parent := range stateMachine.parents {
+		if parent == state {
+			return true
+		}
+	}
+	return false
+}
+
+// exportedEdge is a drawn edge, merging the transition rule, the event rules and the timeouts between two states
+type exportedEdge[S comparable] struct {
+	Edge[S]
+	events []string
+}
+
+func (stateMachine *Machine[S]) exportedEdges() []*exportedEdge[S] {
+	edges := []*exportedEdge[S]{}
+	byEdge := map[Edge[S]]*exportedEdge[S]{}
+	for _, rule := range stateMachine.Rules() {
+		edge, ok := byEdge[Edge[S]{rule.From, rule.To}]
+		if !ok {
+			edge = &exportedEdge[S]{Edge: Edge[S]{rule.From, rule.To}}
+			byEdge[Edge[S]{rule.From, rule.To}] = edge
+			edges = append(edges, edge)
+		}
+		if rule.Event != "" {
+			edge.events = append(edge.events, string(rule.Event))
+		}
+		if rule.After != 0 {
+			edge.events = append(edge.events, string(TimeoutEvent(rule.After)))
+		}
+	}
+	return edges
+}
+
+// edgeLabel label an edge with its events and mark it when guards are registered on it
+func (stateMachine *Machine[S]) edgeLabel(edge *exportedEdge[S]) string {
+	label := strings.Join(edge.events, ", ")
+	if stateMachine.IsGuarded(edge.From, edge.To) {
+		label = strings.TrimSpace(label + " [guarded]")
+	}
+	return label
+}
@@ -0,0 +1,134 @@
+package behavioral
+
+// lineage return the state followed by its ancestors, from the innermost to the outermost
+func (stateMachine *Machine[S]) lineage(state S) []S {
+	lineage := []S{state}
+	for len(lineage) <= len(stateMachine.parents) {
+		parent, ok := stateMachine.parents[state]
+		if !ok {
+			break
+		}
+		lineage = append(lineage, parent)
+		state = parent
+	}
+	return lineage
+}
+
+// entryLeaf follow the history or the initial children of a composite state down to the state really entered
+func (stateMachine *Machine[S]) entryLeaf(state S) S {
+	for i := 0; i <= len(stateMachine.initialChildren)+len(stateMachine.history); i++ {
+		if remembered, ok := stateMachine.history[state]; ok {
+			if stateMachine.historyKinds[state] == DeepHistory {
+				return remembered
+			}
+			state = remembered
+			continue
+		}
+		child, ok := stateMachine.initialChildren[state]
+		if !ok {
+			break
+		}
+		state = child
+	}
+	return state
+}
+
+// transitionPaths return the states exited from the innermost and the states entered from the outermost
+// when a rule of source targeting target lead to leaf. States containing both source and target are
+// neither exited nor entered, a transition from a state to itself or to one of its children exit and re-enter it
+func (stateMachine *Machine[S]) transitionPaths(source, target, leaf S) ([]S, []S) {
+	sourceAncestors := stateMachine.lineage(source)[1:]
+	targetAncestors := stateMachine.lineage(target)[1:]
+
+	var domain *S
+	for i := range sourceAncestors {
+		if contains(targetAncestors, sourceAncestors[i]) {
+			domain = &sourceAncestors[i]
+			break
+		}
+	}
+
+	exited := []S{}
+	for _, state := range stateMachine.lineage(stateMachine.CurrentState) {
+		if domain != nil && state == *domain {
+			break
+		}
+		exited = append(exited, state)
+	}
+
+	entered := []S{}
+	for _, state := range stateMachine.lineage(leaf) {
+		if domain != nil && state == *domain {
+			break
+		}
+		entered = append([]S{state}, entered...)
+	}
+
+	return exited, entered
+}
+
+// IsIn tell if the current state, or the current state of an active region, is the given state or one of its children
+func (stateMachine *Machine[S]) IsIn(state S) bool {
+	if contains(stateMachine.lineage(stateMachine.CurrentState), state) {
+		return true
+	}
+	for _, region := range stateMachine.activeRegions() {
+		if region.IsIn(state) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parent return the composite state containing the given state
+func (stateMachine *Machine[S]) Parent(state S) (S, bool) {
+	parent, ok := stateMachine.parents[state]
+	return parent, ok
+}
+
+// Children return the states directly nested into the given state, sorted by name
+func (stateMachine *Machine[S]) Children(state S) []S {
+	children := []S{}
+	for child, parent := range stateMachine.parents {
+		if parent == state {
+			children = append(children, child)
+		}
+	}
+	stateMachine.sortStates(children)
+	return children
+}
+
+func contains[S comparable](states []S, state S) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// SetParent nest child into parent, transitions and events defined on parent apply to child
+func (builder *MachineBuilder[S]) SetParent(child, parent S) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		if stateMachine.parents == nil {
+			stateMachine.parents = make(map[S]S)
+		}
+		stateMachine.parents[child] = parent
+	})
+
+	return builder
+}
+
+// SetInitialChild define the child entered when a transition target the composite parent state
+func (builder *MachineBuilder[S]) SetInitialChild(parent, child S) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		if stateMachine.initialChildren == nil {
+			stateMachine.initialChildren = make(map[S]S)
+		}
+		stateMachine.initialChildren[parent] = child
+	})
+
+	return builder
+}
@@ -0,0 +1,150 @@
+package behavioral
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransitionRecord is an entry of the transition log, To is the state really entered
+type TransitionRecord[S comparable] struct {
+	From, To S
+	Event    Event
+	At       time.Time
+	Metadata map[string]string
+}
+
+// TransitionMetadata can be implemented by payloads to describe why a transition happened in the log
+type TransitionMetadata interface {
+	TransitionMetadata() map[string]string
+}
+
+// TransitionLog is an append-only log of the transitions of a machine, an error returned
+// by Append roll the machine back to the state it was in before the transition
+type TransitionLog[S comparable] interface {
+	Append(record TransitionRecord[S]) error
+	Records() []TransitionRecord[S]
+}
+
+// MemoryTransitionLog keep the records in memory, it is safe for concurrent use
+type MemoryTransitionLog[S comparable] struct {
+	mutex   sync.RWMutex
+	records []TransitionRecord[S]
+}
+
+func (log *MemoryTransitionLog[S]) Append(record TransitionRecord[S]) error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	log.records = append(log.records, record)
+	return nil
+}
+
+func (log *MemoryTransitionLog[S]) Records() []TransitionRecord[S] {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+	return append([]TransitionRecord[S]{}, log.records...)
+}
+
+type ReplayError[S comparable] struct {
+	Index     int
+	Record    TransitionRecord[S]
+	Err       error
+	stateName func(S) string
+}
+
+func (e *ReplayError[S]) Error() string {
+
+	return fmt.Sprintf("%s: %s", strings.ToUpper(fmt.Sprintf("Replay of Record %d from %s to %s Failed", e.Index, e.stateName(e.Record.From), e.stateName(e.Record.To))), e.Err)
+}
+
+func (e *ReplayError[S]) Unwrap() error {
+	return e.Err
+}
+
+func (stateMachine *Machine[S]) record(transition Transition[S]) error {
+	if stateMachine.transitionLog == nil {
+		return nil
+	}
+	record := TransitionRecord[S]{From: transition.From, To: transition.To, Event: transition.Event, At: stateMachine.now()}
+	switch payload := transition.Payload.(type) {
+	case TransitionMetadata:
+		record.Metadata = payload.TransitionMetadata()
+	case map[string]string:
+		record.Metadata = payload
+	}
+	return stateMachine.transitionLog.Append(record)
+}
+
+// TransitionLog return the log set on the builder, nil when transitions are not recorded
+func (stateMachine *Machine[S]) TransitionLog() TransitionLog[S] {
+	return stateMachine.transitionLog
+}
+
+// Replay rebuild the current state by applying the records from the current state, as the
+// initial state of a freshly built machine. Records are checked against the current rules, guards
+// and hooks are not run and nothing is appended to the log. The machine is left untouched on error
+func (stateMachine *Machine[S]) Replay(records []TransitionRecord[S]) error {
+	initialState := stateMachine.CurrentState
+	initialHistory := map[S]S{}
+	for state, child := range stateMachine.history {
+		initialHistory[state] = child
+	}
+
+	for i, record := range records {
+		if err := stateMachine.replay(record); err != nil {
+			stateMachine.CurrentState = initialState
+			stateMachine.history = initialHistory
+			return &ReplayError[S]{i, record, err, stateMachine.StateName}
+		}
+	}
+	return nil
+}
+
+func (stateMachine *Machine[S]) replay(record TransitionRecord[S]) error {
+	if record.From != stateMachine.CurrentState {
+		return &UnexpectedStateError[S]{record.From, stateMachine.CurrentState, stateMachine.StateName}
+	}
+
+	// The record hold the state really entered, the rule may target one of its ancestors
+	for _, target := range stateMachine.lineage(record.To) {
+		source, allowed := stateMachine.resolveTransition(target)
+		if record.Event != "" {
+			var eventTarget S
+			source, eventTarget, allowed = stateMachine.resolveEvent(record.Event)
+			if !allowed {
+				source, eventTarget, allowed = stateMachine.resolveTimeout(record.Event)
+			}
+			allowed = allowed && eventTarget == target
+		}
+		if allowed {
+			stateMachine.jump(source, target, record.To)
+			return nil
+		}
+	}
+
+	_, _, knownEvent := stateMachine.resolveEvent(record.Event)
+	_, _, knownTimeout := stateMachine.resolveTimeout(record.Event)
+	if record.Event != "" && !knownEvent && !knownTimeout {
+		return &UnknownEventError[S]{record.Event, record.From, stateMachine.StateName}
+	}
+	return &TransitionError[S]{record.From, record.To, stateMachine.StateName}
+}
+
+// jump move to leaf following the rule of source targeting target, only the histories are updated:
+// guards, hooks, regions, timeouts and the log are left out
+func (stateMachine *Machine[S]) jump(source, target, leaf S) {
+	exited, _ := stateMachine.transitionPaths(source, target, leaf)
+	stateMachine.commitHistory(stateMachine.rememberHistory(exited))
+	stateMachine.CurrentState = leaf
+}
+
+// SetTransitionLog record every transition of the machine into the log
+func (builder *MachineBuilder[S]) SetTransitionLog(transitionLog TransitionLog[S]) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		stateMachine.transitionLog = transitionLog
+	})
+
+	return builder
+}
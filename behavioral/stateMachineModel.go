@@ -0,0 +1,245 @@
+package behavioral
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StateMachineModel explore the definition of a machine to generate test sequences, starting from the
+// state the machine was in when the model was created. Paths follow the rules only: guards are not evaluated,
+// the driver of the test give the payloads they need. Regions are not explored, a model can be made of each region machine
+type StateMachineModel[S comparable] struct {
+	stateMachine *Machine[S]
+}
+
+// ModelStep is a step of a generated path: the rule to follow and the state entered by following it.
+// A rule with an Event is followed with Fire, a rule with After by advancing the clock, GoTo(To) otherwise
+type ModelStep[S comparable] struct {
+	Rule[S]
+	State S
+}
+
+func NewStateMachineModel[S comparable](stateMachine *Machine[S]) *StateMachineModel[S] {
+	return &StateMachineModel[S]{stateMachine.modelCopy()}
+}
+
+// modelCopy copy the definition and the runtime state of the machine, without its hooks, observers, log and timers
+func (stateMachine *Machine[S]) modelCopy() *Machine[S] {
+	model := *stateMachine
+	model.history = map[S]S{}
+	for state, remembered := range stateMachine.history {
+		model.history[state] = remembered
+	}
+	model.guards, model.onEnter, model.onExit, model.onTransition = nil, nil, nil, nil
+	model.transitionLog = nil
+	model.observers = Observable[Observer]{}
+	model.entered, model.timers, model.timeoutDispatch = nil, nil, nil
+	return &model
+}
+
+// availableRules return the rules the machine would follow from its current state, a rule of a state
+// is hidden by a rule with the same target or event in one of its children
+func (stateMachine *Machine[S]) availableRules() []Rule[S] {
+	available := []Rule[S]{}
+	lineage := stateMachine.lineage(stateMachine.CurrentState)
+	for _, rule := range stateMachine.Rules() {
+		if !contains(lineage, rule.From) {
+			continue
+		}
+		if bounded, inBounds := stateMachine.inBounds(rule.To); bounded && !inBounds {
+			continue
+		}
+		source, ok := rule.From, true
+		switch {
+		case rule.Event != "":
+			source, _, ok = stateMachine.resolveEvent(rule.Event)
+		case rule.After == 0:
+			source, ok = stateMachine.resolveTransition(rule.To)
+		}
+		if ok && source == rule.From {
+			available = append(available, rule)
+		}
+	}
+	return available
+}
+
+func (stateMachine *Machine[S]) step(rule Rule[S]) ModelStep[S] {
+	stateMachine.jump(rule.From, rule.To, stateMachine.entryLeaf(rule.To))
+	return ModelStep[S]{rule, stateMachine.CurrentState}
+}
+
+// Paths return every path of depth steps, and the shorter paths ending in a state without exits
+func (model *StateMachineModel[S]) Paths(depth int) [][]ModelStep[S] {
+	paths := [][]ModelStep[S]{}
+	var walk func(stateMachine *Machine[S], path []ModelStep[S])
+	walk = func(stateMachine *Machine[S], path []ModelStep[S]) {
+		rules := stateMachine.availableRules()
+		if len(path) == depth || len(rules) == 0 {
+			if len(path) > 0 {
+				paths = append(paths, append([]ModelStep[S]{}, path...))
+			}
+			return
+		}
+		for _, rule := range rules {
+			next := stateMachine.modelCopy()
+			walk(next, append(path, next.step(rule)))
+		}
+	}
+	walk(model.stateMachine.modelCopy(), []ModelStep[S]{})
+	return paths
+}
+
+// InvalidTransitions return, for every state the machine can rest in, the GoTo targets and the events it refuse.
+// To is the zero state for a refused event
+func (model *StateMachineModel[S]) InvalidTransitions() []Rule[S] {
+	invalid := []Rule[S]{}
+	states := model.stateMachine.States()
+	events := []Event{}
+	if model.stateMachine.EventRules != nil {
+		for event := range *model.stateMachine.EventRules {
+			events = append(events, event)
+		}
+		sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	}
+
+	stateMachine := model.stateMachine.modelCopy()
+	stateMachine.history = nil
+	for _, state := range states {
+		if stateMachine.entryLeaf(state) != state {
+			continue
+		}
+		stateMachine.CurrentState = state
+		for _, target := range states {
+			if _, ok := stateMachine.resolveTransition(target); !ok {
+				invalid = append(invalid, Rule[S]{From: state, To: target})
+			}
+		}
+		for _, event := range events {
+			if _, _, ok := stateMachine.resolveEvent(event); !ok {
+				invalid = append(invalid, Rule[S]{From: state, Event: event})
+			}
+		}
+	}
+	return invalid
+}
+
+// RandomWalk return a path of length steps choosing each rule at random, the same seed give the same path.
+// The walk stop early in a state without exits
+func (model *StateMachineModel[S]) RandomWalk(seed int64, length int) []ModelStep[S] {
+	random := rand.New(rand.NewSource(seed))
+	stateMachine := model.stateMachine.modelCopy()
+	walk := []ModelStep[S]{}
+	for len(walk) < length {
+		rules := stateMachine.availableRules()
+		if len(rules) == 0 {
+			break
+		}
+		walk = append(walk, stateMachine.step(rules[random.Intn(len(rules))]))
+	}
+	return walk
+}
+
+// Coverage return an observer counting the rules followed by the machines it is subscribed to
+func (model *StateMachineModel[S]) Coverage() *TransitionCoverage[S] {
+	return &TransitionCoverage[S]{stateMachine: model.stateMachine, counts: map[Rule[S]]int{}}
+}
+
+// TransitionCoverage count how many times each rule of a definition is followed, it is safe for concurrent use
+type TransitionCoverage[S comparable] struct {
+	mutex        sync.Mutex
+	stateMachine *Machine[S]
+	counts       map[Rule[S]]int
+}
+
+func (coverage *TransitionCoverage[S]) Notify(data interface{}) {
+	event, ok := data.(TransitionEvent[S])
+	if !ok || event.Rejected {
+		return
+	}
+	if rule, ok := coverage.match(event); ok {
+		coverage.mutex.Lock()
+		defer coverage.mutex.Unlock()
+		coverage.counts[rule]++
+	}
+}
+
+// match find the rule followed by a transition, from the innermost state holding a matching rule
+func (coverage *TransitionCoverage[S]) match(event TransitionEvent[S]) (Rule[S], bool) {
+	rules := coverage.stateMachine.Rules()
+	entered := coverage.stateMachine.lineage(event.To)
+	for _, from := range coverage.stateMachine.lineage(event.From) {
+		for _, rule := range rules {
+			if rule.From != from || !contains(entered, rule.To) {
+				continue
+			}
+			switch {
+			case rule.After != 0 && event.Event == TimeoutEvent(rule.After),
+				rule.After == 0 && rule.Event == event.Event:
+				return rule, true
+			}
+		}
+	}
+	return Rule[S]{}, false
+}
+
+// Count return how many times the rule was followed
+func (coverage *TransitionCoverage[S]) Count(rule Rule[S]) int {
+	coverage.mutex.Lock()
+	defer coverage.mutex.Unlock()
+	return coverage.counts[rule]
+}
+
+func (coverage *TransitionCoverage[S]) Covered() []Rule[S] {
+	return coverage.filter(true)
+}
+
+func (coverage *TransitionCoverage[S]) Uncovered() []Rule[S] {
+	return coverage.filter(false)
+}
+
+func (coverage *TransitionCoverage[S]) filter(covered bool) []Rule[S] {
+	rules := []Rule[S]{}
+	for _, rule := range coverage.stateMachine.Rules() {
+		if (coverage.Count(rule) > 0) == covered {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Ratio return the part of the rules followed at least once, 1 for a definition without rules
+func (coverage *TransitionCoverage[S]) Ratio() float64 {
+	rules := coverage.stateMachine.Rules()
+	if len(rules) == 0 {
+		return 1
+	}
+	return float64(len(coverage.Covered())) / float64(len(rules))
+}
+
+// String report the coverage of every rule
+func (coverage *TransitionCoverage[S]) String() string {
+	rules := coverage.stateMachine.Rules()
+	lines := []string{fmt.Sprintf("transition coverage %d/%d (%.0f%%)", len(coverage.Covered()), len(rules), coverage.Ratio()*100)}
+	for _, rule := range rules {
+		if count := coverage.Count(rule); count > 0 {
+			lines = append(lines, fmt.Sprintf("  covered   %s x%d", coverage.stateMachine.ruleName(rule), count))
+		} else {
+			lines = append(lines, fmt.Sprintf("  uncovered %s", coverage.stateMachine.ruleName(rule)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (stateMachine *Machine[S]) ruleName(rule Rule[S]) string {
+	name := fmt.Sprintf("%s -> %s", stateMachine.StateName(rule.From), stateMachine.StateName(rule.To))
+	switch {
+	case rule.Event != "":
+		name += fmt.Sprintf(" (%s)", rule.Event)
+	case rule.After != 0:
+		name += fmt.Sprintf(" (%s)", TimeoutEvent(rule.After))
+	}
+	return name
+}
@@ -0,0 +1,49 @@
+package behavioral
+
+// TransitionEvent is published to the observers of a machine after each transition attempt,
+// Rejected is set with the error when the transition did not happen. To is the state really entered,
+// it is the zero state when an unknown event is rejected
+type TransitionEvent[S comparable] struct {
+	From, To S
+	Event    Event
+	Payload  interface{}
+	Rejected bool
+	Err      error
+}
+
+// TransitionObserverFunc adapt a typed function to the Observer interface, data that are not
+// TransitionEvent of the same state type are ignored
+type TransitionObserverFunc[S comparable] func(event TransitionEvent[S])
+
+func (f TransitionObserverFunc[S]) Notify(data interface{}) {
+	if event, ok := data.(TransitionEvent[S]); ok {
+		f(event)
+	}
+}
+
+// Subscribe the observer to the transitions of the machine, it is notified with TransitionEvent values
+// synchronously once the transition is done. The transitions of the regions are published by the region machines
+func (stateMachine *Machine[S]) Subscribe(observer Observer) {
+	if stateMachine.observers.Subs == nil {
+		stateMachine.observers = NewObservable[Observer]()
+	}
+	stateMachine.observers.Subscribe(observer)
+}
+
+func (stateMachine *Machine[S]) Unsubscribe(observer Observer) {
+	if stateMachine.observers.Subs != nil {
+		stateMachine.observers.Unsubscribe(observer)
+	}
+}
+
+func (stateMachine *Machine[S]) publish(event TransitionEvent[S]) {
+	if stateMachine.observers.Subs != nil {
+		stateMachine.observers.Notify(event)
+	}
+}
+
+// reject publish the rejected transition and return its error
+func (stateMachine *Machine[S]) reject(transition Transition[S], err error) error {
+	stateMachine.publish(TransitionEvent[S]{transition.From, transition.To, transition.Event, transition.Payload, true, err})
+	return err
+}
@@ -0,0 +1,158 @@
+package behavioral
+
+import "errors"
+
+// HistoryKind define what is remembered of a composite state when it is exited,
+// re-entering the composite state then enter the remembered state instead of the initial child
+type HistoryKind int
+
+const (
+	ShallowHistory HistoryKind = iota // Remember the direct child that was active
+	DeepHistory                       // Remember the innermost state that was active
+)
+
+// region is an orthogonal region of a composite state, a machine active at the same time as its parent
+type region[S comparable] struct {
+	machine *Machine[S]
+	initial S
+}
+
+// rememberHistory compute the history of the exited composite states, exited start with the current state
+func (stateMachine *Machine[S]) rememberHistory(exited []S) map[S]S {
+	remembered := map[S]S{}
+	for i := 1; i < len(exited); i++ {
+		kind, ok := stateMachine.historyKinds[exited[i]]
+		if !ok {
+			continue
+		}
+		if kind == DeepHistory {
+			remembered[exited[i]] = exited[0]
+		} else {
+			remembered[exited[i]] = exited[i-1]
+		}
+	}
+	return remembered
+}
+
+func (stateMachine *Machine[S]) commitHistory(remembered map[S]S) {
+	if len(remembered) == 0 {
+		return
+	}
+	if stateMachine.history == nil {
+		stateMachine.history = make(map[S]S)
+	}
+	for state, child := range remembered {
+		stateMachine.history[state] = child
+	}
+}
+
+// enterRegions restart the regions of the entered states from their initial state,
+// unless the state has an history in which case the regions resume where they were
+func (stateMachine *Machine[S]) enterRegions(entered []S) {
+	for _, state := range entered {
+		if _, ok := stateMachine.historyKinds[state]; ok {
+			continue
+		}
+		for _, region := range stateMachine.regions[state] {
+			region.machine.CurrentState = region.initial
+		}
+	}
+}
+
+func (stateMachine *Machine[S]) activeRegions() []*Machine[S] {
+	if len(stateMachine.regions) == 0 {
+		return nil
+	}
+	active := []*Machine[S]{}
+	for _, state := range stateMachine.lineage(stateMachine.CurrentState) {
+		for _, region := range stateMachine.regions[state] {
+			active = append(active, region.machine)
+		}
+	}
+	return active
+}
+
+// regionOf return the active region able to go to the desired state
+func (stateMachine *Machine[S]) regionOf(desiredState S) *Machine[S] {
+	for _, region := range stateMachine.activeRegions() {
+		if region.regionOf(desiredState) != nil {
+			return region
+		}
+		if _, ok := region.resolveTransition(desiredState); ok {
+			return region
+		}
+	}
+	return nil
+}
+
+// fireRegions offer the event to every active region, handled is false when no region know the event
+func (stateMachine *Machine[S]) fireRegions(event Event, payload interface{}) (bool, error) {
+	handled := false
+	errs := []error{}
+	for _, region := range stateMachine.activeRegions() {
+		if region.CanFire(event) {
+			handled = true
+			if err := region.Fire(event, payload); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return handled, errors.Join(errs...)
+}
+
+// ActiveStates return the current state followed by the current states of the active regions
+func (stateMachine *Machine[S]) ActiveStates() []S {
+	states := []S{stateMachine.CurrentState}
+	for _, region := range stateMachine.activeRegions() {
+		states = append(states, region.ActiveStates()...)
+	}
+	return states
+}
+
+// SetHistory make the composite state remember its active child when it is exited
+func (builder *MachineBuilder[S]) SetHistory(state S, kind HistoryKind) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		if stateMachine.historyKinds == nil {
+			stateMachine.historyKinds = make(map[S]HistoryKind)
+		}
+		stateMachine.historyKinds[state] = kind
+	})
+
+	return builder
+}
+
+// regionCopy copy a region machine for a new parent machine: the definition, hooks and log are shared
+// while the current state, history, nested regions, timers and observer list belong to the copy
+func (stateMachine *Machine[S]) regionCopy() *Machine[S] {
+	machine := *stateMachine
+	machine.history = map[S]S{}
+	for state, remembered := range stateMachine.history {
+		machine.history[state] = remembered
+	}
+	machine.regions = map[S][]*region[S]{}
+	for state, regions := range stateMachine.regions {
+		for _, r := range regions {
+			machine.regions[state] = append(machine.regions[state], &region[S]{r.machine.regionCopy(), r.initial})
+		}
+	}
+	machine.entered, machine.timers, machine.timeoutDispatch = nil, nil, nil
+	if stateMachine.observers.Subs != nil {
+		machine.observers = NewObservable[Observer]()
+		machine.observers.Subs.PushBackList(stateMachine.observers.Subs)
+	}
+	return &machine
+}
+
+// AddRegion add an orthogonal region to a state, the region machine is active while the machine is in the state.
+// Each built machine get its own copy of the region machine, which restart from its current state at Build time
+// each time the state is entered
+func (builder *MachineBuilder[S]) AddRegion(state S, regionStateMachine *Machine[S]) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		regionCopy := regionStateMachine.regionCopy()
+		appendToMap(&stateMachine.regions, state, &region[S]{regionCopy, regionCopy.CurrentState})
+	})
+
+	return builder
+}
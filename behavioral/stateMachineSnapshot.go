@@ -0,0 +1,219 @@
+package behavioral
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot is the runtime state of a machine, separated from its definition. The fingerprint
+// of the definition is stored to refuse restoring a snapshot into an incompatible definition
+type Snapshot[S comparable] struct {
+	Version      string               `json:"version,omitempty"`
+	Fingerprint  string               `json:"fingerprint"`
+	CurrentState S                    `json:"currentState"`
+	History      []SnapshotHistory[S] `json:"history,omitempty"`
+	Entered      []SnapshotEntered[S] `json:"entered,omitempty"`
+	Regions      []Snapshot[S]        `json:"regions,omitempty"`
+}
+
+// SnapshotHistory is the state remembered by a composite state with an history
+type SnapshotHistory[S comparable] struct {
+	State      S `json:"state"`
+	Remembered S `json:"remembered"`
+}
+
+// SnapshotEntered is the time an active state with timeouts was entered at
+type SnapshotEntered[S comparable] struct {
+	State S         `json:"state"`
+	At    time.Time `json:"at"`
+}
+
+type IncompatibleSnapshotError struct {
+	SnapshotVersion, SnapshotFingerprint     string
+	DefinitionVersion, DefinitionFingerprint string
+}
+
+func (e *IncompatibleSnapshotError) Error() string {
+
+	return fmt.Sprintf("%s: snapshot version %q fingerprint %s, definition version %q fingerprint %s", strings.ToUpper("Snapshot can't be Restored into an Incompatible Definition"), e.SnapshotVersion, e.SnapshotFingerprint, e.DefinitionVersion, e.DefinitionFingerprint)
+}
+
+// Fingerprint identify the definition of the machine: version, states, rules, timeouts, hierarchy, histories,
+// terminal states and regions. Guards and hooks are code and are not part of the fingerprint
+func (stateMachine *Machine[S]) Fingerprint() string {
+	hash := sha256.Sum256([]byte(stateMachine.canonicalDefinition()))
+	return hex.EncodeToString(hash[:8])
+}
+
+func (stateMachine *Machine[S]) canonicalDefinition() string {
+	lines := []string{"version " + stateMachine.version}
+	for _, state := range stateMachine.definedStates() {
+		line := fmt.Sprintf("state %#v", state)
+		if parent, ok := stateMachine.parents[state]; ok {
+			line += fmt.Sprintf(" parent %#v", parent)
+		}
+		if child, ok := stateMachine.initialChildren[state]; ok {
+			line += fmt.Sprintf(" initial %#v", child)
+		}
+		if kind, ok := stateMachine.historyKinds[state]; ok {
+			line += fmt.Sprintf(" history %d", kind)
+		}
+		if stateMachine.IsTerminal(state) {
+			line += " terminal"
+		}
+		for _, region := range stateMachine.regions[state] {
+			line += fmt.Sprintf(" region %#v %s", region.initial, region.machine.Fingerprint())
+		}
+		lines = append(lines, line)
+	}
+	for _, rule := range stateMachine.Rules() {
+		line := fmt.Sprintf("rule %#v %#v %q", rule.From, rule.To, rule.Event)
+		if rule.After != 0 {
+			line += " after " + rule.After.String()
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// Snapshot capture the current state, the histories, the entry times of the states with timeouts and the regions of the machine
+func (stateMachine *Machine[S]) Snapshot() Snapshot[S] {
+	snapshot := Snapshot[S]{
+		Version:      stateMachine.version,
+		Fingerprint:  stateMachine.Fingerprint(),
+		CurrentState: stateMachine.CurrentState,
+	}
+	for _, state := range stateMachine.States() {
+		if remembered, ok := stateMachine.history[state]; ok {
+			snapshot.History = append(snapshot.History, SnapshotHistory[S]{state, remembered})
+		}
+		if at, ok := stateMachine.entered[state]; ok {
+			snapshot.Entered = append(snapshot.Entered, SnapshotEntered[S]{state, at})
+		}
+	}
+	for _, region := range stateMachine.allRegions() {
+		snapshot.Regions = append(snapshot.Regions, region.machine.Snapshot())
+	}
+	return snapshot
+}
+
+// Restore the runtime state of a snapshot taken from a machine with the same definition.
+// The timeouts of the active states are re-armed for the time they still have to wait
+// since the state was entered, the expired ones fire at the next tick of the clock
+func (stateMachine *Machine[S]) Restore(snapshot Snapshot[S]) error {
+	if err := stateMachine.checkSnapshot(snapshot); err != nil {
+		return err
+	}
+	stateMachine.StopTimeouts()
+	stateMachine.restore(snapshot)
+	stateMachine.resumeTimeouts()
+	return nil
+}
+
+// checkSnapshot check the whole snapshot before anything is restored
+func (stateMachine *Machine[S]) checkSnapshot(snapshot Snapshot[S]) error {
+	if fingerprint := stateMachine.Fingerprint(); snapshot.Fingerprint != fingerprint {
+		return &IncompatibleSnapshotError{snapshot.Version, snapshot.Fingerprint, stateMachine.version, fingerprint}
+	}
+	states := stateMachine.States()
+	if !contains(states, snapshot.CurrentState) {
+		return &StateError[S]{snapshot.CurrentState, stateMachine.StateName}
+	}
+	for _, history := range snapshot.History {
+		for _, state := range []S{history.State, history.Remembered} {
+			if !contains(states, state) {
+				return &StateError[S]{state, stateMachine.StateName}
+			}
+		}
+	}
+	for _, entered := range snapshot.Entered {
+		if !contains(states, entered.State) {
+			return &StateError[S]{entered.State, stateMachine.StateName}
+		}
+	}
+	regions := stateMachine.allRegions()
+	if len(regions) != len(snapshot.Regions) {
+		return &IncompatibleSnapshotError{snapshot.Version, snapshot.Fingerprint, stateMachine.version, stateMachine.Fingerprint()}
+	}
+	for i, region := range regions {
+		if err := region.machine.checkSnapshot(snapshot.Regions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (stateMachine *Machine[S]) restore(snapshot Snapshot[S]) {
+	stateMachine.CurrentState = snapshot.CurrentState
+	stateMachine.history = nil
+	for _, history := range snapshot.History {
+		stateMachine.commitHistory(map[S]S{history.State: history.Remembered})
+	}
+	stateMachine.entered = nil
+	for _, entered := range snapshot.Entered {
+		if stateMachine.entered == nil {
+			stateMachine.entered = make(map[S]time.Time)
+		}
+		stateMachine.entered[entered.State] = entered.At
+	}
+	for i, region := range stateMachine.allRegions() {
+		region.machine.restore(snapshot.Regions[i])
+	}
+}
+
+// allRegions return the regions of every state, active or not, in a stable order
+func (stateMachine *Machine[S]) allRegions() []*region[S] {
+	regions := []*region[S]{}
+	for _, state := range stateMachine.States() {
+		regions = append(regions, stateMachine.regions[state]...)
+	}
+	return regions
+}
+
+// MarshalJSON encode the snapshot of the machine, the definition is not encoded
+func (stateMachine *Machine[S]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stateMachine.Snapshot())
+}
+
+// UnmarshalJSON restore a snapshot into a machine already built with the same definition
+func (stateMachine *Machine[S]) UnmarshalJSON(data []byte) error {
+	var snapshot Snapshot[S]
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	return stateMachine.Restore(snapshot)
+}
+
+// GobEncode encode the snapshot of the machine, the definition is not encoded
+func (stateMachine *Machine[S]) GobEncode() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(stateMachine.Snapshot())
+	return buffer.Bytes(), err
+}
+
+// GobDecode restore a snapshot into a machine already built with the same definition
+func (stateMachine *Machine[S]) GobDecode(data []byte) error {
+	var snapshot Snapshot[S]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return err
+	}
+	return stateMachine.Restore(snapshot)
+}
+
+// SetVersion name the version of the definition, it is stored in snapshots and part of the fingerprint
+func (builder *MachineBuilder[S]) SetVersion(version string) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		stateMachine.version = version
+	})
+
+	return builder
+}
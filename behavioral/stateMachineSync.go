@@ -0,0 +1,127 @@
+package behavioral
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SyncStateMachine is a StateMachine safe for concurrent use, every operation is atomic.
+// Guards, hooks and observers are run while the machine is locked, they must not call the SyncStateMachine back.
+// Fired timeouts take the lock as any other operation and wake up the waiters, the machine is wrapped
+// before its first timeout can fire
+type SyncStateMachine[S comparable] struct {
+	mutex        sync.Mutex
+	stateMachine *Machine[S]
+	changed      chan struct{}
+}
+
+func NewSyncStateMachine[S comparable](stateMachine *Machine[S]) *SyncStateMachine[S] {
+	syncStateMachine := &SyncStateMachine[S]{stateMachine: stateMachine, changed: make(chan struct{})}
+	stateMachine.setTimeoutDispatch(func(fire func()) {
+		syncStateMachine.Do(func(*Machine[S]) error {
+			fire()
+			return nil
+		})
+	})
+	return syncStateMachine
+}
+
+type UnexpectedStateError[S comparable] struct {
+	Expected, Current S
+	stateName         func(S) string
+}
+
+func (e *UnexpectedStateError[S]) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("State %s was Expected but Current State is %s", e.stateName(e.Expected), e.stateName(e.Current)))
+}
+
+// Do run fn with exclusive access to the machine, waiters are notified afterwards
+func (syncStateMachine *SyncStateMachine[S]) Do(fn func(stateMachine *Machine[S]) error) error {
+	syncStateMachine.mutex.Lock()
+	defer syncStateMachine.mutex.Unlock()
+	defer syncStateMachine.notify()
+	return fn(syncStateMachine.stateMachine)
+}
+
+// notify wake up the waiters, the mutex must be held
+func (syncStateMachine *SyncStateMachine[S]) notify() {
+	close(syncStateMachine.changed)
+	syncStateMachine.changed = make(chan struct{})
+}
+
+func (syncStateMachine *SyncStateMachine[S]) Current() S {
+	syncStateMachine.mutex.Lock()
+	defer syncStateMachine.mutex.Unlock()
+	return syncStateMachine.stateMachine.CurrentState
+}
+
+func (syncStateMachine *SyncStateMachine[S]) IsIn(state S) bool {
+	syncStateMachine.mutex.Lock()
+	defer syncStateMachine.mutex.Unlock()
+	return syncStateMachine.stateMachine.IsIn(state)
+}
+
+func (syncStateMachine *SyncStateMachine[S]) CanFire(event Event) bool {
+	syncStateMachine.mutex.Lock()
+	defer syncStateMachine.mutex.Unlock()
+	return syncStateMachine.stateMachine.CanFire(event)
+}
+
+func (syncStateMachine *SyncStateMachine[S]) String() string {
+	syncStateMachine.mutex.Lock()
+	defer syncStateMachine.mutex.Unlock()
+	return syncStateMachine.stateMachine.String()
+}
+
+func (syncStateMachine *SyncStateMachine[S]) GoTo(desiredState S) error {
+	return syncStateMachine.GoToWith(desiredState, nil)
+}
+
+func (syncStateMachine *SyncStateMachine[S]) GoToWith(desiredState S, payload interface{}) error {
+	return syncStateMachine.Do(func(stateMachine *Machine[S]) error {
+		return stateMachine.GoToWith(desiredState, payload)
+	})
+}
+
+func (syncStateMachine *SyncStateMachine[S]) Fire(event Event, payload interface{}) error {
+	return syncStateMachine.Do(func(stateMachine *Machine[S]) error {
+		return stateMachine.Fire(event, payload)
+	})
+}
+
+// TransitionIf go to the desired state only if the current state is still the expected one,
+// an UnexpectedStateError is returned otherwise
+func (syncStateMachine *SyncStateMachine[S]) TransitionIf(expectedCurrent, desiredState S) error {
+	return syncStateMachine.TransitionIfWith(expectedCurrent, desiredState, nil)
+}
+
+func (syncStateMachine *SyncStateMachine[S]) TransitionIfWith(expectedCurrent, desiredState S, payload interface{}) error {
+	return syncStateMachine.Do(func(stateMachine *Machine[S]) error {
+		if stateMachine.CurrentState != expectedCurrent {
+			return &UnexpectedStateError[S]{expectedCurrent, stateMachine.CurrentState, stateMachine.StateName}
+		}
+		return stateMachine.GoToWith(desiredState, payload)
+	})
+}
+
+// WaitFor block until the machine is in the given state (IsIn) or the context is done
+func (syncStateMachine *SyncStateMachine[S]) WaitFor(ctx context.Context, state S) error {
+	for {
+		syncStateMachine.mutex.Lock()
+		if syncStateMachine.stateMachine.IsIn(state) {
+			syncStateMachine.mutex.Unlock()
+			return nil
+		}
+		changed := syncStateMachine.changed
+		syncStateMachine.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
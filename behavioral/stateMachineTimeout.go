@@ -0,0 +1,217 @@
+package behavioral
+
+import (
+	"sort"
+	"time"
+)
+
+// timeout is a transition taken once the machine stayed in a state for a duration
+type timeout[S comparable] struct {
+	after  time.Duration
+	target S
+}
+
+// TimeoutEvent is the event of the transitions taken by a timeout, as seen by the hooks and the transition log
+func TimeoutEvent(after time.Duration) Event {
+	return Event("after " + after.String())
+}
+
+func (stateMachine *Machine[S]) getClock() Clock {
+	if stateMachine.clock == nil {
+		return SystemClock{}
+	}
+	return stateMachine.clock
+}
+
+func (stateMachine *Machine[S]) now() time.Time {
+	return stateMachine.getClock().Now()
+}
+
+// ArmTimeouts start the timeouts of the active states as if they were just entered. Entering a state arm its
+// timeouts: transitions and Restore do it by themselves, BuildStarted call ArmTimeouts for the initial state
+// and a machine made with Build or BuildChecked must call it once. Timers hold a pointer to the machine,
+// it must not be copied once they are armed
+func (stateMachine *Machine[S]) ArmTimeouts() {
+	stateMachine.StopTimeouts()
+	stateMachine.entered = nil
+	stateMachine.enterTimeouts(stateMachine.lineage(stateMachine.CurrentState))
+	for _, region := range stateMachine.activeRegions() {
+		region.ArmTimeouts()
+	}
+}
+
+// StopTimeouts cancel the armed timeouts of the machine and of its regions
+func (stateMachine *Machine[S]) StopTimeouts() {
+	for state := range stateMachine.timers {
+		stateMachine.stopTimeouts(state)
+	}
+	for _, region := range stateMachine.allRegions() {
+		region.machine.StopTimeouts()
+	}
+}
+
+// updateTimeouts cancel the timeouts of the exited states and arm the ones of the entered states
+func (stateMachine *Machine[S]) updateTimeouts(exited, entered []S) {
+	for _, state := range exited {
+		stateMachine.stopTimeouts(state)
+		delete(stateMachine.entered, state)
+		for _, region := range stateMachine.regions[state] {
+			region.machine.StopTimeouts()
+		}
+	}
+	stateMachine.enterTimeouts(entered)
+	for _, state := range entered {
+		for _, region := range stateMachine.regions[state] {
+			region.machine.ArmTimeouts()
+		}
+	}
+}
+
+func (stateMachine *Machine[S]) enterTimeouts(entered []S) {
+	now := stateMachine.now()
+	for _, state := range entered {
+		if len(stateMachine.timeouts[state]) == 0 {
+			continue
+		}
+		if stateMachine.entered == nil {
+			stateMachine.entered = make(map[S]time.Time)
+		}
+		stateMachine.entered[state] = now
+		stateMachine.armTimeouts(state, 0)
+	}
+}
+
+// resumeTimeouts arm the timeouts of the active states for the time they still have to wait,
+// the states without entry time are considered as just entered
+func (stateMachine *Machine[S]) resumeTimeouts() {
+	now := stateMachine.now()
+	for _, state := range stateMachine.lineage(stateMachine.CurrentState) {
+		if len(stateMachine.timeouts[state]) == 0 {
+			continue
+		}
+		if _, ok := stateMachine.entered[state]; !ok {
+			stateMachine.enterTimeouts([]S{state})
+			continue
+		}
+		stateMachine.armTimeouts(state, now.Sub(stateMachine.entered[state]))
+	}
+	for _, region := range stateMachine.activeRegions() {
+		region.resumeTimeouts()
+	}
+}
+
+func (stateMachine *Machine[S]) armTimeouts(state S, elapsed time.Duration) {
+	enteredAt := stateMachine.entered[state]
+	for _, armed := range stateMachine.timeouts[state] {
+		armed := armed
+		timer := stateMachine.getClock().AfterFunc(armed.after-elapsed, func() {
+			stateMachine.dispatchTimeout(func() {
+				stateMachine.fireTimeout(state, enteredAt, armed)
+			})
+		})
+		appendToMap(&stateMachine.timers, state, timer)
+	}
+}
+
+func (stateMachine *Machine[S]) stopTimeouts(state S) {
+	for _, timer := range stateMachine.timers[state] {
+		timer.Stop()
+	}
+	delete(stateMachine.timers, state)
+}
+
+// dispatchTimeout run a fired timeout, through the wrapper owning the machine when there is one
+func (stateMachine *Machine[S]) dispatchTimeout(fire func()) {
+	if stateMachine.timeoutDispatch != nil {
+		stateMachine.timeoutDispatch(fire)
+		return
+	}
+	fire()
+}
+
+// setTimeoutDispatch make the fired timeouts of the machine and of its regions run through dispatch
+func (stateMachine *Machine[S]) setTimeoutDispatch(dispatch func(fire func())) {
+	stateMachine.timeoutDispatch = dispatch
+	for _, region := range stateMachine.allRegions() {
+		region.machine.setTimeoutDispatch(dispatch)
+	}
+}
+
+// fireTimeout take the transition of the timeout if the machine is still in the state entered at enteredAt,
+// a timer that fired while it was being stopped is ignored this way. A timeout rejected by a guard or a hook is dropped
+func (stateMachine *Machine[S]) fireTimeout(state S, enteredAt time.Time, armed timeout[S]) {
+	if at, ok := stateMachine.entered[state]; !ok || !at.Equal(enteredAt) {
+		return
+	}
+	if !contains(stateMachine.lineage(stateMachine.CurrentState), state) {
+		return
+	}
+	_ = stateMachine.transition(state, armed.target, TimeoutEvent(armed.after), nil)
+}
+
+// resolveTimeout return the state holding the timeout of the event and its target, searching from the current state up to its ancestors
+func (stateMachine *Machine[S]) resolveTimeout(event Event) (S, S, bool) {
+	for _, state := range stateMachine.lineage(stateMachine.CurrentState) {
+		for _, armed := range stateMachine.timeouts[state] {
+			if TimeoutEvent(armed.after) == event {
+				return state, armed.target, true
+			}
+		}
+	}
+	var none S
+	return none, none, false
+}
+
+// timeoutRules return the timeouts as rules, sorted by state name then duration
+func (stateMachine *Machine[S]) timeoutRules() []Rule[S] {
+	rules := []Rule[S]{}
+	for state, timeouts := range stateMachine.timeouts {
+		for _, armed := range timeouts {
+			rules = append(rules, Rule[S]{From: state, To: armed.target, After: armed.after})
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if from, other := stateMachine.StateName(rules[i].From), stateMachine.StateName(rules[j].From); from != other {
+			return from < other
+		}
+		return rules[i].After < rules[j].After
+	})
+	return rules
+}
+
+// SetClock give the time to the timeouts and the transition log, the SystemClock is used by default.
+// With the SystemClock the timeouts fire from their own goroutine: a machine with timeouts
+// used concurrently must be wrapped in a SyncStateMachine. Timeouts of the initial state run once
+// the machine is started, see BuildStarted
+func (builder *MachineBuilder[S]) SetClock(clock Clock) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		stateMachine.clock = clock
+	})
+
+	return builder
+}
+
+// AddTimeout go from the state to the target once the machine stayed in the state for the given duration.
+// The timeout of a composite state run while the machine is in any of its children, it is cancelled
+// when the state is exited. Guards and hooks of the state to target transition apply.
+// The timeouts of the initial state are only armed by BuildStarted, or by ArmTimeouts after Build
+func (builder *MachineBuilder[S]) AddTimeout(state S, after time.Duration, target S) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		appendToMap(&stateMachine.timeouts, state, timeout[S]{after, target})
+	})
+
+	return builder
+}
+
+// BuildStarted build a checked machine and arm the timeouts of its initial state, machines with timeouts
+// are built this way: the timers hold a pointer to the returned machine
+func (builder *MachineBuilder[S]) BuildStarted() (*Machine[S], error) {
+	stateMachine, err := builder.BuildChecked()
+	if err != nil {
+		return nil, err
+	}
+	stateMachine.ArmTimeouts()
+	return &stateMachine, nil
+}
@@ -0,0 +1,225 @@
+package behavioral
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type StateError[S comparable] struct {
+	State     S
+	stateName func(S) string
+}
+
+func (e *StateError[S]) Error() string {
+
+	return strings.ToUpper(fmt.Sprintf("State %s is Invalid", e.stateName(e.State)))
+}
+
+// ValidationReport list the issues of a machine definition, an empty report is a valid definition
+type ValidationReport[S comparable] struct {
+	InvalidInitialState *StateError[S] // The initial state is not declared
+	UnreachableStates   []S            // States that can't be reached from the initial state
+	UndeclaredTargets   []Rule[S]      // Rules targeting a state out of the declared states
+	MissingNames        []S            // States without name while a state to string mapping is set
+	DeadEnds            []S            // States without exits that are not marked as terminal
+	stateName           func(S) string
+}
+
+func (report *ValidationReport[S]) Valid() bool {
+	return report.InvalidInitialState == nil &&
+		len(report.UnreachableStates) == 0 &&
+		len(report.UndeclaredTargets) == 0 &&
+		len(report.MissingNames) == 0 &&
+		len(report.DeadEnds) == 0
+}
+
+// Err return the report as an error, nil when the definition is valid
+func (report *ValidationReport[S]) Err() error {
+	if report.Valid() {
+		return nil
+	}
+	return report
+}
+
+func (report *ValidationReport[S]) Error() string {
+	issues := []string{}
+	if report.InvalidInitialState != nil {
+		issues = append(issues, "initial "+report.InvalidInitialState.Error())
+	}
+	if len(report.UnreachableStates) > 0 {
+		issues = append(issues, "unreachable states: "+report.names(report.UnreachableStates))
+	}
+	if len(report.UndeclaredTargets) > 0 {
+		rules := make([]string, len(report.UndeclaredTargets))
+		for i, rule := range report.UndeclaredTargets {
+			rules[i] = fmt.Sprintf("%s -> %s", report.stateName(rule.From), report.stateName(rule.To))
+		}
+		issues = append(issues, "undeclared targets: "+strings.Join(rules, ", "))
+	}
+	if len(report.MissingNames) > 0 {
+		issues = append(issues, "missing names: "+report.names(report.MissingNames))
+	}
+	if len(report.DeadEnds) > 0 {
+		issues = append(issues, "dead ends: "+report.names(report.DeadEnds))
+	}
+	return "INVALID STATE MACHINE DEFINITION: " + strings.Join(issues, "; ")
+}
+
+func (report *ValidationReport[S]) names(states []S) string {
+	names := make([]string, len(states))
+	for i, state := range states {
+		names[i] = report.stateName(state)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (report *ValidationReport[S]) merge(other *ValidationReport[S]) {
+	if report.InvalidInitialState == nil {
+		report.InvalidInitialState = other.InvalidInitialState
+	}
+	report.UnreachableStates = append(report.UnreachableStates, other.UnreachableStates...)
+	report.UndeclaredTargets = append(report.UndeclaredTargets, other.UndeclaredTargets...)
+	report.MissingNames = append(report.MissingNames, other.MissingNames...)
+	report.DeadEnds = append(report.DeadEnds, other.DeadEnds...)
+}
+
+// Validate check the machine definition, the current state is considered as the initial state.
+// Regions are validated from their own initial state
+func (stateMachine *Machine[S]) Validate() *ValidationReport[S] {
+	report := &ValidationReport[S]{stateName: stateMachine.StateName}
+	states := stateMachine.States()
+
+	if !stateMachine.isDeclared(stateMachine.CurrentState) {
+		report.InvalidInitialState = &StateError[S]{stateMachine.CurrentState, stateMachine.StateName}
+	}
+
+	reachable := stateMachine.reachableStates()
+	for _, state := range states {
+		if !contains(reachable, state) {
+			report.UnreachableStates = append(report.UnreachableStates, state)
+		}
+	}
+
+	for _, rule := range stateMachine.Rules() {
+		if !stateMachine.isDeclared(rule.To) {
+			report.UndeclaredTargets = append(report.UndeclaredTargets, rule)
+		}
+	}
+
+	if stateMachine.stateToString != nil {
+		for _, state := range states {
+			if _, ok := (*stateMachine.stateToString)[state]; !ok {
+				report.MissingNames = append(report.MissingNames, state)
+			}
+		}
+	}
+
+	for _, state := range states {
+		if stateMachine.isComposite(state) || stateMachine.IsTerminal(state) {
+			continue
+		}
+		if len(stateMachine.exits(state)) == 0 {
+			report.DeadEnds = append(report.DeadEnds, state)
+		}
+	}
+
+	for _, regions := range stateMachine.regions {
+		for _, region := range regions {
+			initial := *region.machine
+			initial.CurrentState = region.initial
+			report.merge(initial.Validate())
+		}
+	}
+
+	return report
+}
+
+// exits return the targets of the rules and events of a state and of its ancestors
+func (stateMachine *Machine[S]) exits(state S) []S {
+	lineage := stateMachine.lineage(state)
+	exits := []S{}
+	for _, rule := range stateMachine.Rules() {
+		if contains(lineage, rule.From) {
+			exits = append(exits, rule.To)
+		}
+	}
+	return exits
+}
+
+// reachableStates walk the rules from the current state, entering a state also enter its ancestors and its initial children.
+// The history remembered at runtime is ignored, a composite state can always be entered through its initial child
+func (stateMachine *Machine[S]) reachableStates() []S {
+	definition := *stateMachine
+	definition.history = nil
+	reachable := []S{}
+	toVisit := []S{stateMachine.CurrentState}
+	for len(toVisit) > 0 {
+		state := toVisit[0]
+		toVisit = toVisit[1:]
+		for _, entered := range append(definition.lineage(definition.entryLeaf(state)), state) {
+			if contains(reachable, entered) {
+				continue
+			}
+			reachable = append(reachable, entered)
+			toVisit = append(toVisit, stateMachine.exits(entered)...)
+		}
+	}
+	return reachable
+}
+
+// isDeclared tell if a state is part of the declared states: below MaxUnreachableState when it is set
+// on an integer state type, or in the state to string mapping when it is set
+func (stateMachine *Machine[S]) isDeclared(state S) bool {
+	if bounded, inBounds := stateMachine.inBounds(state); bounded {
+		return inBounds
+	}
+	if stateMachine.stateToString != nil {
+		_, ok := (*stateMachine.stateToString)[state]
+		return ok
+	}
+	return true
+}
+
+// inBounds check 0 <= state < MaxUnreachableState, bounded is false when the state type is not an integer
+// or MaxUnreachableState is not set
+func (stateMachine *Machine[S]) inBounds(state S) (bounded bool, inBounds bool) {
+	var none S
+	if stateMachine.MaxUnreachableState == none {
+		return false, true
+	}
+	value, max := reflect.ValueOf(state), reflect.ValueOf(stateMachine.MaxUnreachableState)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true, value.Int() >= 0 && value.Int() < max.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true, value.Uint() < max.Uint()
+	}
+	return false, true
+}
+
+// IsTerminal tell if the state has been marked as terminal, a terminal state is expected to have no exits
+func (stateMachine *Machine[S]) IsTerminal(state S) bool {
+	return contains(stateMachine.terminalStates, state)
+}
+
+func (builder *MachineBuilder[S]) SetTerminalStates(terminalStates ...S) *MachineBuilder[S] {
+
+	builder.AddAction(func(stateMachine *Machine[S]) {
+		stateMachine.terminalStates = terminalStates
+	})
+
+	return builder
+}
+
+// Validate build a machine and check its definition
+func (builder *MachineBuilder[S]) Validate() *ValidationReport[S] {
+	stateMachine := builder.Build()
+	return stateMachine.Validate()
+}
+
+// BuildChecked build a machine and return the validation report as error when the definition is not valid
+func (builder *MachineBuilder[S]) BuildChecked() (Machine[S], error) {
+	stateMachine := builder.Build()
+	return stateMachine, stateMachine.Validate().Err()
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/Zando74/generic-patterns/behavioral"
 )
@@ -72,6 +74,39 @@ func (adminModifier *IsAdminModifier) Handle(q *behavioral.Query[UserLoginReques
 	q.Error = fmt.Errorf("UNAUTHORIZED")
 }
 
+// A handler implementing HandleContext receive the context given to FireContext
+type sessionLookupModifier struct {
+	Latency time.Duration // Time taken by the session store
+}
+
+func (sessionLookup *sessionLookupModifier) Handle(q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	sessionLookup.HandleContext(context.Background(), q)
+}
+
+func (sessionLookup *sessionLookupModifier) HandleContext(ctx context.Context, q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	select {
+	case <-time.After(sessionLookup.Latency):
+	case <-ctx.Done():
+		q.Error = ctx.Err()
+	}
+}
+
+// The chain stop as soon as the request is cancelled or its deadline passed
+func TimedRouteCheck(user User, timeout time.Duration, sessionLatency time.Duration) error {
+	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
+	accessBroker.Subscribe(&sessionLookupModifier{Latency: sessionLatency})
+	accessBroker.Subscribe(&isAuthModifier{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	q := behavioral.Query[UserLoginRequestData, UserLoginResultData]{
+		Data: UserLoginRequestData{Username: user.Name, Roles: user.Roles},
+	}
+	accessBroker.FireContext(ctx, &q)
+	return q.Error
+}
+
 func AuthenticatedRouteCheck(user User) error {
 	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
 	accessBroker.Subscribe(&isAuthModifier{})
@@ -117,4 +152,7 @@ func MainChainOfResponsibilityBrokerExample() {
 	Scenario(*john)
 	Scenario(*jane)
 
+	fmt.Println(TimedRouteCheck(*john, time.Second, time.Millisecond))         // Output: <nil>
+	fmt.Println(TimedRouteCheck(*john, time.Millisecond, 50*time.Millisecond)) // Output: context deadline exceeded
+
 }
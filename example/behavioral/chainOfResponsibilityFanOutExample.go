@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// PRICE QUOTE USE CASE
+
+type QuoteRequest struct {
+	Product  string
+	Quantity int
+}
+
+type Quote struct {
+	Provider string
+	Price    float64
+}
+
+type quoteProvider struct {
+	Name      string
+	UnitPrice float64
+	Latency   time.Duration
+	Err       error
+}
+
+func (provider *quoteProvider) Handle(q *behavioral.Query[QuoteRequest, Quote]) {
+	provider.HandleContext(context.Background(), q)
+}
+
+func (provider *quoteProvider) HandleContext(ctx context.Context, q *behavioral.Query[QuoteRequest, Quote]) {
+	select {
+	case <-time.After(provider.Latency):
+	case <-ctx.Done(): // The broker cancel the providers once the policy is decided
+		q.Error = ctx.Err()
+		return
+	}
+	if provider.Err != nil {
+		q.Error = provider.Err
+		return
+	}
+	q.Result = Quote{provider.Name, provider.UnitPrice * float64(q.Data.Quantity)}
+}
+
+// The reducer combine the partial results, here the cheapest quote is kept
+func CheapestQuote(quotes []Quote) Quote {
+	cheapest := quotes[0]
+	for _, quote := range quotes[1:] {
+		if quote.Price < cheapest.Price {
+			cheapest = quote
+		}
+	}
+	return cheapest
+}
+
+func QuoteBroker(policy behavioral.FanOutPolicy) *behavioral.FanOutBroker[QuoteRequest, Quote] {
+	quoteBroker := behavioral.NewFanOutBroker[QuoteRequest, Quote](policy, CheapestQuote)
+	quoteBroker.Subscribe(&quoteProvider{Name: "Fast", UnitPrice: 12, Latency: time.Millisecond})
+	quoteBroker.Subscribe(&quoteProvider{Name: "Cheap", UnitPrice: 9, Latency: 20 * time.Millisecond})
+	quoteBroker.Subscribe(&quoteProvider{Name: "Down", Err: fmt.Errorf("PROVIDER DOWN")})
+	return quoteBroker
+}
+
+func MainChainOfResponsibilityFanOutExample() {
+
+	for _, policy := range []behavioral.FanOutPolicy{behavioral.FirstSuccess, behavioral.Quorum(2), behavioral.AllMustSucceed, behavioral.CollectErrors} {
+		q := behavioral.Query[QuoteRequest, Quote]{Data: QuoteRequest{Product: "Chair", Quantity: 10}}
+		QuoteBroker(policy).Fire(&q) // Every provider is asked concurrently
+		fmt.Println(q.Result, q.Error)
+	}
+	// Output:
+	// {Fast 120} <nil>
+	// {Cheap 90} <nil>
+	// { 0} PROVIDER DOWN
+	// {Cheap 90} PROVIDER DOWN
+
+	q := behavioral.Query[QuoteRequest, Quote]{Data: QuoteRequest{Product: "Chair", Quantity: 10}}
+	QuoteBroker(behavioral.Quorum(3)).Fire(&q)
+	fmt.Println(q.Error) // Output: QUORUM OF 3 NOT REACHED, 0 SUCCEEDED: PROVIDER DOWN (decided as soon as a provider failed)
+
+}
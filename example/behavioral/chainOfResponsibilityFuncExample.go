@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+func hasRole(role string) func(q *LoginQuery) bool {
+	return func(q *LoginQuery) bool { return slices.Contains(q.Data.Roles, role) }
+}
+
+// The admin route of AdminRouteCheck, assembled without declaring a handler type
+func DeclarativeAdminBroker() *behavioral.Broker[UserLoginRequestData, UserLoginResultData] {
+	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
+
+	accessBroker.Subscribe(behavioral.RequireThat(func(q *LoginQuery) bool {
+		return hasRole("user")(q) || hasRole("admin")(q)
+	}, errors.New("UNAUTHENTICATED")))
+	accessBroker.Subscribe(behavioral.Transform(func(data UserLoginRequestData, result UserLoginResultData) (UserLoginResultData, error) {
+		result.IsAuth = true
+		return result, nil
+	}))
+	accessBroker.Subscribe(behavioral.When(hasRole("admin"), behavioral.HandlerFunc[UserLoginRequestData, UserLoginResultData](func(q *LoginQuery) {
+		q.Result.IsAdmin = true
+	})))
+	accessBroker.Subscribe(behavioral.RequireThat(func(q *LoginQuery) bool { return q.Result.IsAdmin }, errors.New("UNAUTHORIZED")))
+	return accessBroker
+}
+
+func MainChainOfResponsibilityFuncExample() {
+	accessBroker := DeclarativeAdminBroker()
+
+	for _, user := range []User{{Name: "Jack", Roles: []string{"user", "admin"}}, {Name: "Jane", Roles: []string{"user"}}, {Name: "Joe"}} {
+		q := LoginQuery{Data: UserLoginRequestData{Username: user.Name, Roles: user.Roles}}
+		accessBroker.Fire(&q)
+		fmt.Println(user.Name, q.Result, q.Error)
+	}
+	// Output:
+	// Jack  { IsAuth: true, IsAdmin: true } <nil>
+	// Jane  { IsAuth: true, IsAdmin: false } UNAUTHORIZED
+	// Joe  { IsAuth: false, IsAdmin: false } UNAUTHENTICATED
+}
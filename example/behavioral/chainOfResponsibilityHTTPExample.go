@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// The user is given by the X-User header and its roles by the X-Roles header
+func ExtractLoginRequest(r *http.Request) (UserLoginRequestData, error) {
+	username := r.Header.Get("X-User")
+	if username == "" {
+		return UserLoginRequestData{}, errors.New("X-USER HEADER MISSING")
+	}
+	roles := []string{}
+	if header := r.Header.Get("X-Roles"); header != "" {
+		roles = strings.Split(header, ",")
+	}
+	return UserLoginRequestData{Username: username, Roles: roles}, nil
+}
+
+// The admin route is only reached by admins, with the result of the chain in its context
+func AdminRoute() http.Handler {
+	requireAdmin := behavioral.NewHTTPMiddleware(DeclarativeAdminBroker(), ExtractLoginRequest, nil)
+
+	return requireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, _ := behavioral.QueryResultFromContext[UserLoginResultData](r.Context())
+		fmt.Fprintf(w, "Welcome admin%s", result)
+	}))
+}
+
+func MainChainOfResponsibilityHTTPExample() {
+	route := AdminRoute()
+
+	for _, headers := range []map[string]string{
+		{"X-User": "Jack", "X-Roles": "user,admin"},
+		{"X-User": "Jane", "X-Roles": "user"},
+		{},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		route.ServeHTTP(recorder, request)
+		fmt.Println(recorder.Code, strings.TrimSpace(recorder.Body.String()))
+	}
+	// Output:
+	// 200 Welcome admin { IsAuth: true, IsAdmin: true }
+	// 403 Forbidden
+	// 400 Bad Request
+}
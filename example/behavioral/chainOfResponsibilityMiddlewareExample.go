@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+type LoginQuery = behavioral.Query[UserLoginRequestData, UserLoginResultData]
+
+// A middleware run code around the rest of the chain
+func Timing(q *LoginQuery, next func()) {
+	start := time.Now()
+	next()
+	fmt.Printf("%s checked in %s, error: %v\n", q.Data.Username, time.Since(start).Round(time.Hour), q.Error)
+}
+
+// Not calling next end the chain successfully
+type sessionCache struct {
+	Sessions map[string]UserLoginResultData
+}
+
+func (cache *sessionCache) Handle(q *LoginQuery, next func()) {
+	if result, ok := cache.Sessions[q.Data.Username]; ok {
+		q.Result = result
+		return
+	}
+	next()
+	if q.Error == nil {
+		cache.Sessions[q.Data.Username] = q.Result
+	}
+}
+
+func MainChainOfResponsibilityMiddlewareExample() {
+
+	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
+	accessBroker.Subscribe(&isAuthModifier{})
+	accessBroker.Subscribe(&IsAdminModifier{})
+
+	// Middlewares and the existing handlers and brokers coexist in a chain
+	accessChain := behavioral.NewChain[UserLoginRequestData, UserLoginResultData](
+		behavioral.MiddlewareFunc[UserLoginRequestData, UserLoginResultData](Timing),
+		&sessionCache{Sessions: map[string]UserLoginResultData{}},
+	)
+	accessChain.Use(behavioral.BrokerMiddleware(accessBroker))
+
+	for _, user := range []User{*NewUser("Jane", []string{"admin"}, nil), *NewUser("Jane", nil, nil), *NewUser("John", []string{"user"}, nil)} {
+		q := LoginQuery{Data: UserLoginRequestData{Username: user.Name, Roles: user.Roles}}
+		accessChain.Fire(&q)
+	}
+	// Output: the second check of Jane is served by the cache, the broker is not run
+	// Jane checked in 0s, error: <nil>
+	// Jane checked in 0s, error: <nil>
+	// John checked in 0s, error: UNAUTHORIZED
+
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// A buggy handler, it panic for users without roles
+type primaryRoleModifier struct{}
+
+func (primaryRole *primaryRoleModifier) Handle(q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	q.Result.IsAdmin = q.Data.Roles[0] == "admin"
+}
+
+func PanicScenario(policy behavioral.PanicPolicy) error {
+	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
+	accessBroker.SetPanicPolicy(policy)
+	accessBroker.SubscribeNamed("primary role", 10, &primaryRoleModifier{})
+	accessBroker.Subscribe(&isAuthModifier{})
+
+	q := behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jack"}}
+	accessBroker.Fire(&q)
+	return q.Error
+}
+
+func MainChainOfResponsibilityPanicExample() {
+
+	// The panic is converted into an error carrying the handler name and the stack trace
+	err := PanicScenario(behavioral.RecoverPanic)
+	fmt.Println(err) // Output: HANDLER PRIMARY ROLE PANICKED: runtime error: index out of range [0] with length 0
+
+	var panicErr *behavioral.HandlerPanicError
+	if errors.As(err, &panicErr) {
+		fmt.Println(len(panicErr.Stack) > 0) // Output: true
+	}
+
+	// The panicking handler is skipped, the rest of the chain decide
+	fmt.Println(PanicScenario(behavioral.SkipPanickingHandler)) // Output: UNAUTHENTICATED
+
+	// behavioral.PropagatePanic is the default, the panic go through Fire
+
+}
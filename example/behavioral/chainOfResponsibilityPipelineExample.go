@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+type auditModifier struct{}
+
+func (audit *auditModifier) Handle(q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	fmt.Printf("Audit: %s%s\n", q.Data.Username, q.Result)
+}
+
+type bannedUserModifier struct {
+	Banned []string
+}
+
+func (banned *bannedUserModifier) Handle(q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	for _, name := range banned.Banned {
+		if name == q.Data.Username {
+			q.Error = fmt.Errorf("BANNED")
+		}
+	}
+}
+
+func MainChainOfResponsibilityPipelineExample() {
+
+	// The core team register the authorization steps with a name and a priority, the highest run first
+	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
+	accessBroker.SubscribeNamed("authentication", 100, &isAuthModifier{})
+	accessBroker.SubscribeNamed("authorization", 50, &IsAdminModifier{})
+
+	// Other modules contribute steps relative to the named ones, without coordinating the registration order
+	accessBroker.SubscribeNamed("audit", -100, &auditModifier{})
+	accessBroker.InsertAfter("authentication", "ban", &bannedUserModifier{Banned: []string{"Joe"}})
+
+	err := accessBroker.SubscribeNamed("audit", 0, &auditModifier{})
+	fmt.Println(err) // Output: HANDLER AUDIT IS ALREADY SUBSCRIBED
+
+	fmt.Println(accessBroker.Pipeline()) // Output: [authentication (100) ban (100) authorization (50) audit (-100)]
+
+	// Replace keep the position of the handler, Remove drop it by name
+	accessBroker.Replace("ban", &bannedUserModifier{Banned: []string{"Jane"}})
+
+	q := behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jane", Roles: []string{"admin"}}}
+	accessBroker.Fire(&q)
+	fmt.Println(q.Error) // Output: BANNED
+
+	accessBroker.Remove("ban")
+
+	q = behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jane", Roles: []string{"admin"}}}
+	accessBroker.Fire(&q) // Output: Audit: Jane { IsAuth: true, IsAdmin: true }
+
+}
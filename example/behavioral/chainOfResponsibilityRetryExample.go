@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+var ErrDirectoryUnavailable = errors.New("DIRECTORY UNAVAILABLE")
+
+// A remote user directory, unavailable for its first calls
+type directoryModifier struct {
+	Unavailable int
+	Calls       int
+}
+
+func (directory *directoryModifier) Handle(q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	directory.Calls++
+	if directory.Calls <= directory.Unavailable {
+		q.Error = ErrDirectoryUnavailable
+		return
+	}
+	q.Result.IsAuth = true
+}
+
+// Used when the directory stay unavailable, users are let in without admin rights
+type degradedModeModifier struct{}
+
+func (degradedMode *degradedModeModifier) Handle(q *behavioral.Query[UserLoginRequestData, UserLoginResultData]) {
+	q.Result.IsAuth, q.Result.IsAdmin = true, false
+}
+
+func DirectoryRetryPolicy(clock *behavioral.ManualClock) behavioral.RetryPolicy {
+	return behavioral.RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Jitter:         0.2,
+		Retryable:      func(err error) bool { return errors.Is(err, ErrDirectoryUnavailable) },
+		Sleeper:        clock,                         // Advance the clock instead of waiting
+		Random:         func() float64 { return 0.5 }, // No jitter, the waits are predictable
+	}
+}
+
+func MainChainOfResponsibilityRetryExample() {
+	clock := behavioral.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	start := clock.Now()
+
+	// The directory answer on the third attempt, after waiting 100ms then 200ms
+	directory := &directoryModifier{Unavailable: 2}
+	accessBroker := behavioral.NewBroker[UserLoginRequestData, UserLoginResultData]()
+	accessBroker.Subscribe(behavioral.NewRetryHandler[UserLoginRequestData, UserLoginResultData](directory, DirectoryRetryPolicy(clock), nil))
+
+	q := behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jack"}}
+	accessBroker.Fire(&q)
+	fmt.Println(q.Result, q.Error, directory.Calls, clock.Now().Sub(start)) // Output:  { IsAuth: true, IsAdmin: false } <nil> 3 300ms
+
+	// Without fallback, the last error is recorded once the attempts are exhausted
+	directory = &directoryModifier{Unavailable: 10}
+	retry := behavioral.NewRetryHandler[UserLoginRequestData, UserLoginResultData](directory, DirectoryRetryPolicy(clock), nil)
+	q = behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jack"}}
+	retry.Handle(&q)
+	fmt.Println(q.Error, errors.Is(q.Error, ErrDirectoryUnavailable)) // Output: HANDLER FAILED AFTER 4 ATTEMPTS: DIRECTORY UNAVAILABLE true
+
+	// With a fallback, the chain continue in degraded mode
+	retry.Fallback = &degradedModeModifier{}
+	q = behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jack"}}
+	retry.Handle(&q)
+	fmt.Println(q.Result, q.Error) // Output:  { IsAuth: true, IsAdmin: false } <nil>
+
+	// Errors that are not retryable end the retries at once
+	retry.Handler, retry.Fallback = &isAuthModifier{}, nil
+	q = behavioral.Query[UserLoginRequestData, UserLoginResultData]{Data: UserLoginRequestData{Username: "Jack"}}
+	retry.Handle(&q)
+	fmt.Println(q.Error) // Output: HANDLER FAILED AFTER 1 ATTEMPTS: UNAUTHENTICATED
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+func MainStateMachineActorExample() {
+
+	document, _ := NewDocument("Actor Document", "The workflow of this document run in its own goroutine", Draft)
+	document.Reviewers = 2
+
+	// The same definition run as an actor, events are handled one at a time from a mailbox of 16 events
+	actor := behavioral.NewStateMachineActor(document.State, 16)
+
+	actor.Send(Submit, nil) // Fire and forget, a MailboxFullError is returned when the mailbox is full
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	state, err := actor.Ask(ctx, Approve, document) // Wait for the event to be handled
+	fmt.Println(DocumentStateToString[state], err)  // Output: Approved <nil>
+
+	_, err = actor.Ask(ctx, Submit, nil)
+	fmt.Println(err) // Output: EVENT SUBMIT IS UNKNOWN IN STATE APPROVED
+
+	actor.Send(Publish, nil)
+	actor.Stop(ctx) // The events already in the mailbox are handled before the actor stops
+
+	fmt.Println(DocumentStateToString[actor.Current()]) // Output: Published
+
+	err = actor.Send(Rework, nil)
+	fmt.Println(err) // Output: STATE MACHINE ACTOR IS STOPPED
+
+}
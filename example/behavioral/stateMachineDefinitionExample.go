@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// A workflow definition product owners can review, states are referenced by name
+const DocumentWorkflowYAML = `
+initial: Draft
+states: [Draft, Moderation, Approved, Rejected, Published]
+terminal: [Published]
+transitions:
+  - from: Draft
+    to: Moderation
+    event: submit
+  - from: Moderation
+    to: Approved
+    event: approve
+  - from: Moderation
+    to: Rejected
+    event: reject
+  - from: Rejected
+    to: Draft
+    event: rework
+  - from: Approved
+    to: Published
+    event: publish
+`
+
+// The same workflow with a typo in a state name
+var MistypedDocumentWorkflowYAML = strings.Replace(DocumentWorkflowYAML, "to: Rejected", "to: Rejcted", 1)
+
+func MainStateMachineDefinitionExample() {
+
+	// Definitions are checked when they are loaded, errors point to the faulty field
+	_, err := behavioral.LoadStateMachineDefinition([]byte(MistypedDocumentWorkflowYAML), behavioral.YAMLDefinitionDecoder)
+	fmt.Println(err) // Output: INVALID STATE MACHINE DEFINITION at transitions[2].to (line 13): state "Rejcted" is not declared in states
+
+	// JSONDefinitionDecoder is also available, any other decoder can be plugged
+	definition, err := behavioral.LoadStateMachineDefinition([]byte(DocumentWorkflowYAML), behavioral.YAMLDefinitionDecoder)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// The codec convert the names of the definition into states, here through the state to string mapping of the int based states
+	builder, err := behavioral.NewStateMachineBuilderFromDefinition(definition, behavioral.NewNamedStateCodec(&DocumentStateToString))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	documentStateMachine, err := builder.
+		AddGuard(Moderation, Approved, HasEnoughReviewers). // Guards and hooks are code, they are added on the builder
+		BuildChecked()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	documentStateMachine.Fire(Submit, nil)
+	fmt.Println(documentStateMachine.String()) // Output: Moderation
+
+	// A machine built in code can be dumped to the same format
+	orderDefinition := behavioral.NewStateMachineDefinition(NewOrder("ORD-003").Status, behavioral.StringStateCodec[OrderStatus]{})
+
+	fmt.Println(string(orderDefinition.YAML()))
+	// initial: Created
+	// states:
+	//   - Cancelled
+	//   ...
+
+}
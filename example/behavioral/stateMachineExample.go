@@ -7,20 +7,33 @@ import (
 )
 
 type Document struct {
-	Title   string
-	Content string
+	Title     string
+	Content   string
+	Reviewers int
 	// ...
 	State *behavioral.StateMachine
 }
 
 func (d *Document) Moderate(approuved bool) error {
 	if approuved {
-		return d.State.GoTo(Approved)
+		return d.State.Fire(Approve, d) // The machine decides the target state, the document is given to the guards of the transition
 	} else {
-		return d.State.GoTo(Rejected)
+		return d.State.Fire(Reject, d)
 	}
 }
 
+// A guard can veto a transition allowed by the transition rules
+func HasEnoughReviewers(stateMachine *behavioral.StateMachine, payload interface{}) error {
+	document, ok := payload.(*Document)
+	if !ok {
+		return fmt.Errorf("the document to approve is required")
+	}
+	if document.Reviewers < 2 {
+		return fmt.Errorf("at least 2 reviewers are required")
+	}
+	return nil
+}
+
 // Define the different possible states of the document
 const (
 	Draft behavioral.State = iota
@@ -56,14 +69,39 @@ var DocumentStateTransitionRules = map[behavioral.State][]behavioral.State{
 	},
 }
 
+// Define the events a document can receive
+const (
+	Submit  behavioral.Event = "submit"
+	Approve behavioral.Event = "approve"
+	Reject  behavioral.Event = "reject"
+	Rework  behavioral.Event = "rework"
+	Publish behavioral.Event = "publish"
+)
+
+// Define the target state of each event for each source state
+var DocumentEventRules = map[behavioral.Event]map[behavioral.State]behavioral.State{
+	Submit:  {Draft: Moderation},
+	Approve: {Moderation: Approved},
+	Reject:  {Moderation: Rejected},
+	Rework:  {Rejected: Draft},
+	Publish: {Approved: Published},
+}
+
 func NewDocument(title, content string, state behavioral.State) (*Document, error) {
 
-	documentStateMachine := (&behavioral.StateMachineBuilder{}).
-		SetCurrentState(state).                            // Set the current state
-		SetTransitionRules(&DocumentStateTransitionRules). // Set the transition rules between states
-		SetStateToString(&DocumentStateToString).          // Set the mapping between state and string representation
-		SetMaxUnreachableState(MAX_BUILD_STATUS).          // Set the maximum unreachable state (prevent invalid state value)
-		Build()                                            // We can use dedicated builder Functionnal pattern to create a new StateMachine
+	documentStateMachine, err := (&behavioral.StateMachineBuilder{}).
+		SetCurrentState(state).                             // Set the current state
+		SetTransitionRules(&DocumentStateTransitionRules).  // Set the transition rules between states
+		SetEventRules(&DocumentEventRules).                 // Set the events and their target states
+		SetStateToString(&DocumentStateToString).           // Set the mapping between state and string representation
+		SetMaxUnreachableState(MAX_BUILD_STATUS).           // Set the maximum unreachable state (prevent invalid state value)
+		AddGuard(Moderation, Approved, HasEnoughReviewers). // Guard the transition from Moderation to Approved
+		SetTerminalStates(Published).                       // Published is expected to have no exits
+		BuildChecked()                                      // We can use dedicated builder Functionnal pattern to create a new StateMachine, the definition is validated
+
+	if err != nil {
+		return nil, err // Output: INVALID STATE MACHINE DEFINITION: ... when the definition is inconsistent
+	}
 
 	return &Document{
 		Title:   title,
@@ -77,16 +115,32 @@ func MainStateMachineExample() {
 
 	draftDocument, _ := NewDocument("Draft Document", "This is a draft document", Draft) // You can start from any state
 
-	draftDocument.State.GoTo(Moderation) // Transition from Draft to Moderation
+	draftDocument.State.Fire(Submit, nil) // Transition from Draft to Moderation
+
+	err := draftDocument.Moderate(true) // Rejected by the guard, the document stays in Moderation
+
+	if err != nil {
+		fmt.Println(err) // Output: TRANSITION FROM MODERATION TO APPROVED IS REJECTED: at least 2 reviewers are required
+	}
+
+	draftDocument.Reviewers = 2
 
 	draftDocument.Moderate(true) // Transition from Moderation to Approved
 
 	fmt.Println(draftDocument.State) // StateMachine implement Stringer Interface String() return currentState as string specified from mapping
 
-	err := draftDocument.State.GoTo(Draft) // Trying to do invalid transition from Approved to Draft
+	err = draftDocument.State.GoTo(Draft) // Trying to do invalid transition from Approved to Draft
+
+	if err != nil {
+		fmt.Println(err) // Output: TRANSITION FROM APPROVED TO DRAFT IS NOT ALLOWED
+	}
+
+	fmt.Println(draftDocument.State.CanFire(Publish)) // Output: true
+
+	err = draftDocument.State.Fire(Submit, nil) // Submit is not defined for Approved
 
 	if err != nil {
-		fmt.Println(err) // Output: Transition from Moderation to Published is not allowed
+		fmt.Println(err) // Output: EVENT SUBMIT IS UNKNOWN IN STATE APPROVED
 	}
 
 }
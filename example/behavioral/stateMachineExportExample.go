@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+)
+
+func MainStateMachineExportExample() {
+
+	document, _ := NewDocument("Exported Document", "This document workflow is rendered as a diagram", Moderation)
+
+	// Render the workflow, names come from the state to string mapping, the current state is highlighted
+	fmt.Println(document.State.DOT())     // Graphviz digraph, render it with `dot -Tsvg`
+	fmt.Println(document.State.Mermaid()) // Mermaid stateDiagram-v2, embed it in markdown documentation
+
+	// Any definition can be rendered, whatever the state type
+	fmt.Println(NewOrder("ORD-002").Status.Mermaid())
+
+}
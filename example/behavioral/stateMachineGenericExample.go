@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// Any comparable type can be used as state, no need for a parallel state to string mapping
+type OrderStatus string
+
+const (
+	OrderCreated   OrderStatus = "Created"
+	OrderPaid      OrderStatus = "Paid"
+	OrderShipped   OrderStatus = "Shipped"
+	OrderCancelled OrderStatus = "Cancelled"
+)
+
+var OrderTransitionRules = map[OrderStatus][]OrderStatus{
+	OrderCreated: {
+		OrderPaid, OrderCancelled,
+	},
+	OrderPaid: {
+		OrderShipped, OrderCancelled,
+	},
+}
+
+type Order struct {
+	Reference string
+	Status    *behavioral.Machine[OrderStatus]
+}
+
+// Hooks are run on each transition, an error returned by a hook roll the machine back to its previous state
+func AuditOrderTransition(stateMachine *behavioral.Machine[OrderStatus], transition behavioral.Transition[OrderStatus]) error {
+	fmt.Printf("Audit: order went from %s to %s\n", transition.From, transition.To)
+	return nil
+}
+
+func NotifyCustomer(stateMachine *behavioral.Machine[OrderStatus], transition behavioral.Transition[OrderStatus]) error {
+	fmt.Println("Notification: your order has been shipped")
+	return nil
+}
+
+func NewOrder(reference string) *Order {
+
+	orderStateMachine := (&behavioral.MachineBuilder[OrderStatus]{}).
+		SetCurrentState(OrderCreated).
+		SetTransitionRules(&OrderTransitionRules).
+		OnTransition(OrderCreated, OrderPaid, AuditOrderTransition). // Run when going from Created to Paid
+		OnTransition(OrderPaid, OrderShipped, AuditOrderTransition).
+		OnEnter(OrderShipped, NotifyCustomer). // Run each time Shipped is entered
+		Build()
+
+	return &Order{
+		Reference: reference,
+		Status:    &orderStateMachine,
+	}
+}
+
+func MainGenericStateMachineExample() {
+
+	order := NewOrder("ORD-001")
+
+	order.Status.GoTo(OrderPaid)    // Transition from Created to Paid
+	order.Status.GoTo(OrderShipped) // Transition from Paid to Shipped
+
+	fmt.Println(order.Status) // Output: Shipped
+
+	err := order.Status.GoTo(OrderCancelled) // Trying to cancel a shipped order
+
+	if err != nil {
+		fmt.Println(err) // Output: TRANSITION FROM SHIPPED TO CANCELLED IS NOT ALLOWED
+	}
+
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+type FulfilmentState string
+
+const (
+	FulfilmentPending    FulfilmentState = "Pending"
+	FulfilmentProcessing FulfilmentState = "Processing" // Composite state containing Picking, Packing and Shipping
+	FulfilmentPicking    FulfilmentState = "Picking"
+	FulfilmentPacking    FulfilmentState = "Packing"
+	FulfilmentShipping   FulfilmentState = "Shipping"
+	FulfilmentDelivered  FulfilmentState = "Delivered"
+	FulfilmentCancelled  FulfilmentState = "Cancelled"
+)
+
+var FulfilmentTransitionRules = map[FulfilmentState][]FulfilmentState{
+	FulfilmentPending: {
+		FulfilmentProcessing,
+	},
+	FulfilmentProcessing: {
+		FulfilmentCancelled, // Defined once on the parent, apply to Picking, Packing and Shipping
+	},
+	FulfilmentPicking: {
+		FulfilmentPacking,
+	},
+	FulfilmentPacking: {
+		FulfilmentShipping,
+	},
+	FulfilmentShipping: {
+		FulfilmentDelivered,
+	},
+}
+
+func LogFulfilmentEntry(state FulfilmentState) behavioral.Action[FulfilmentState] {
+	return func(stateMachine *behavioral.Machine[FulfilmentState], transition behavioral.Transition[FulfilmentState]) error {
+		fmt.Printf("Entered %s\n", state)
+		return nil
+	}
+}
+
+func MainHierarchicalStateMachineExample() {
+
+	fulfilmentStateMachine := (&behavioral.MachineBuilder[FulfilmentState]{}).
+		SetCurrentState(FulfilmentPending).
+		SetTransitionRules(&FulfilmentTransitionRules).
+		SetParent(FulfilmentPicking, FulfilmentProcessing). // Nest Picking, Packing and Shipping into Processing
+		SetParent(FulfilmentPacking, FulfilmentProcessing).
+		SetParent(FulfilmentShipping, FulfilmentProcessing).
+		SetInitialChild(FulfilmentProcessing, FulfilmentPicking). // Entering Processing enter Picking
+		OnEnter(FulfilmentProcessing, LogFulfilmentEntry(FulfilmentProcessing)).
+		OnEnter(FulfilmentPicking, LogFulfilmentEntry(FulfilmentPicking)).
+		Build()
+
+	fulfilmentStateMachine.GoTo(FulfilmentProcessing) // Output: Entered Processing, Entered Picking (parent is entered first)
+
+	fmt.Println(fulfilmentStateMachine.String())                   // Output: Picking
+	fmt.Println(fulfilmentStateMachine.IsIn(FulfilmentProcessing)) // Output: true
+
+	fulfilmentStateMachine.GoTo(FulfilmentPacking)
+
+	err := fulfilmentStateMachine.GoTo(FulfilmentCancelled) // Allowed by the rule of Processing
+
+	fmt.Println(err, fulfilmentStateMachine.String()) // Output: <nil> Cancelled
+
+}
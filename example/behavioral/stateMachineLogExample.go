@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// A payload can describe why a transition happened, it is stored in the transition log
+type OrderPayment struct {
+	TransactionID string
+}
+
+func (p OrderPayment) TransitionMetadata() map[string]string {
+	return map[string]string{"transaction": p.TransactionID}
+}
+
+func NewAuditedOrderStateMachine(transitionLog behavioral.TransitionLog[OrderStatus]) behavioral.Machine[OrderStatus] {
+	return (&behavioral.MachineBuilder[OrderStatus]{}).
+		SetCurrentState(OrderCreated).
+		SetTransitionRules(&OrderTransitionRules).
+		SetTransitionLog(transitionLog). // Every transition is appended to the log
+		Build()
+}
+
+func MainStateMachineLogExample() {
+
+	transitionLog := &behavioral.MemoryTransitionLog[OrderStatus]{}
+	orderStateMachine := NewAuditedOrderStateMachine(transitionLog)
+
+	orderStateMachine.GoToWith(OrderPaid, OrderPayment{TransactionID: "TX-42"})
+	orderStateMachine.GoTo(OrderShipped)
+
+	for _, record := range transitionLog.Records() {
+		fmt.Printf("%s -> %s at %s %v\n", record.From, record.To, record.At.Format("15:04:05"), record.Metadata) // Output: Created -> Paid at 10:00:00 map[transaction:TX-42] ...
+	}
+
+	// Rebuild the current state of an order from its stored log
+	restoredStateMachine := NewAuditedOrderStateMachine(&behavioral.MemoryTransitionLog[OrderStatus]{})
+	err := restoredStateMachine.Replay(transitionLog.Records())
+
+	fmt.Println(err, restoredStateMachine.String()) // Output: <nil> Shipped
+
+	// A log containing a transition the rules no longer allow is rejected
+	strictRules := map[OrderStatus][]OrderStatus{OrderCreated: {OrderCancelled}}
+	strictStateMachine := (&behavioral.MachineBuilder[OrderStatus]{}).
+		SetCurrentState(OrderCreated).
+		SetTransitionRules(&strictRules).
+		Build()
+
+	fmt.Println(strictStateMachine.Replay(transitionLog.Records())) // Output: REPLAY OF RECORD 0 FROM CREATED TO PAID FAILED: TRANSITION FROM CREATED TO PAID IS NOT ALLOWED
+
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+func MainStateMachineModelExample() {
+
+	definition, _ := NewDocument("", "", Draft)
+	model := behavioral.NewStateMachineModel(definition.State) // The model start from the current state of the machine
+
+	fmt.Println(len(model.Paths(3)))             // Every path of 3 steps from Draft
+	fmt.Println(len(model.InvalidTransitions())) // Every refused GoTo target and event, to check they are refused by the domain
+	coverage := model.Coverage()                 // An observer counting the rules followed by the machines it is subscribed to
+
+	// Drive documents along seeded random walks, the same seed replay the same walk
+	for seed := int64(0); seed < 2; seed++ {
+		document, _ := NewDocument("Generated Document", "Driven by a random walk", Draft)
+		document.Reviewers = 2
+		document.State.Subscribe(coverage)
+
+		for _, step := range model.RandomWalk(seed, 6) {
+			var err error
+			if step.Event != "" {
+				err = document.State.Fire(step.Event, document)
+			} else {
+				err = document.State.GoToWith(step.To, document)
+			}
+			if err != nil || document.State.CurrentState != step.State {
+				fmt.Println("the document diverged from the model:", err)
+			}
+		}
+	}
+
+	fmt.Println(coverage)
+	// Output:
+	// transition coverage 8/10 (80%)
+	//   uncovered Approved -> Published
+	//   covered   Draft -> Moderation x1
+	//   uncovered Moderation -> Approved
+	//   ...
+
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// Any Observer can subscribe to a machine, it is notified with behavioral.TransitionEvent values
+type DocumentCache struct {
+	Invalidated int
+}
+
+func (c *DocumentCache) Notify(data interface{}) {
+	if event, ok := data.(behavioral.TransitionEvent[behavioral.State]); ok && !event.Rejected {
+		c.Invalidated++
+	}
+}
+
+func MainStateMachineObserverExample() {
+
+	document, _ := NewDocument("Observed Document", "The workflow of this document is observed", Draft)
+
+	cache := &DocumentCache{}
+	document.State.Subscribe(cache)
+
+	// TransitionObserverFunc adapt a typed function to the Observer interface
+	document.State.Subscribe(behavioral.TransitionObserverFunc[behavioral.State](func(event behavioral.TransitionEvent[behavioral.State]) {
+		if event.Rejected {
+			fmt.Printf("%s rejected: %s\n", event.Event, event.Err)
+			return
+		}
+		fmt.Printf("Refresh UI: %s -> %s\n", document.State.StateName(event.From), document.State.StateName(event.To))
+	}))
+
+	document.State.Fire(Submit, nil) // Output: Refresh UI: Draft -> Moderation
+	document.Moderate(true)          // Output: approve rejected: TRANSITION FROM MODERATION TO APPROVED IS REJECTED: at least 2 reviewers are required
+	document.Moderate(false)         // Output: Refresh UI: Moderation -> Rejected
+
+	fmt.Println(cache.Invalidated) // Output: 2
+
+	document.State.Unsubscribe(cache)
+
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+type PlayerState string
+
+const (
+	PlayerOff     PlayerState = "Off"
+	PlayerOn      PlayerState = "On" // Composite state with an orthogonal volume region
+	PlayerPlaying PlayerState = "Playing"
+	PlayerPaused  PlayerState = "Paused"
+	VolumeNormal  PlayerState = "Normal"
+	VolumeMuted   PlayerState = "Muted"
+)
+
+var PlaybackEventRules = map[behavioral.Event]map[PlayerState]PlayerState{
+	"power": {PlayerOff: PlayerOn, PlayerOn: PlayerOff},
+	"pause": {PlayerPlaying: PlayerPaused},
+	"play":  {PlayerPaused: PlayerPlaying},
+}
+
+var VolumeEventRules = map[behavioral.Event]map[PlayerState]PlayerState{
+	"mute":   {VolumeNormal: VolumeMuted},
+	"unmute": {VolumeMuted: VolumeNormal},
+}
+
+func MainRegionStateMachineExample() {
+
+	// The volume region is independent from the playback, it is active while the player is On
+	volumeStateMachine := (&behavioral.MachineBuilder[PlayerState]{}).
+		SetCurrentState(VolumeNormal).
+		SetEventRules(&VolumeEventRules).
+		Build()
+
+	playerStateMachine := (&behavioral.MachineBuilder[PlayerState]{}).
+		SetCurrentState(PlayerOff).
+		SetEventRules(&PlaybackEventRules).
+		SetParent(PlayerPlaying, PlayerOn).
+		SetParent(PlayerPaused, PlayerOn).
+		SetInitialChild(PlayerOn, PlayerPlaying).
+		SetHistory(PlayerOn, behavioral.ShallowHistory). // Turning the player back on resume where it was
+		AddRegion(PlayerOn, &volumeStateMachine).
+		Build()
+
+	playerStateMachine.Fire("power", nil)
+	playerStateMachine.Fire("pause", nil) // Handled by the playback
+	playerStateMachine.Fire("mute", nil)  // Handled by the volume region
+
+	fmt.Println(playerStateMachine.String()) // Output: Paused [Muted]
+
+	playerStateMachine.Fire("power", nil)
+	fmt.Println(playerStateMachine.String()) // Output: Off
+
+	playerStateMachine.Fire("power", nil)
+	fmt.Println(playerStateMachine.String())          // Output: Paused [Muted]
+	fmt.Println(playerStateMachine.IsIn(VolumeMuted)) // Output: true
+
+}
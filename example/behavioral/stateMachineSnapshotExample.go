@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// A stored document, its state machine is encoded as a snapshot of its runtime state
+type StoredDocument struct {
+	Title string
+	State interface{}
+}
+
+func MainStateMachineSnapshotExample() {
+
+	document, _ := NewDocument("Stored Document", "This document is saved in a database", Draft)
+	document.State.Fire(Submit, nil)
+
+	// Only the runtime state and the fingerprint of the definition are stored
+	data, _ := json.Marshal(StoredDocument{Title: document.Title, State: document.State})
+	fmt.Println(string(data)) // Output: {"Title":"Stored Document","State":{"fingerprint":"...","currentState":1}}
+
+	// On load the machine is built from the definition, then the snapshot is restored into it
+	loadedDocument, _ := NewDocument("", "", Draft)
+	err := json.Unmarshal(data, &StoredDocument{State: loadedDocument.State})
+
+	fmt.Println(err, loadedDocument.State.String()) // Output: <nil> Moderation
+
+	// A snapshot taken with another definition is refused instead of producing an invalid current state
+	definition, _ := behavioral.LoadStateMachineDefinition([]byte(DocumentWorkflowYAML), behavioral.YAMLDefinitionDecoder)
+	builder, _ := behavioral.NewStateMachineBuilderFromDefinition(definition, behavioral.NewNamedStateCodec(&DocumentStateToString))
+	otherStateMachine := builder.SetVersion("v2").Build()
+
+	err = json.Unmarshal(data, &StoredDocument{State: &otherStateMachine})
+
+	fmt.Println(err) // Output: SNAPSHOT CAN'T BE RESTORED INTO AN INCOMPATIBLE DEFINITION: snapshot version "" fingerprint ..., definition version "v2" fingerprint ...
+
+	// encoding/gob is supported the same way through GobEncode and GobDecode
+
+}
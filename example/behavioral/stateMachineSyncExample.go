@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+func MainSyncStateMachineExample() {
+
+	document, _ := NewDocument("Shared Document", "This document is moderated by concurrent requests", Draft)
+
+	// The machine can now be shared between goroutines, like HTTP handlers
+	sharedStateMachine := behavioral.NewSyncStateMachine(document.State)
+
+	// Block until the document is published, or give up after a second
+	published := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		published <- sharedStateMachine.WaitFor(ctx, Published)
+	}()
+
+	// Several requests try to submit the document, only one of them succeed
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(request int) {
+			defer wg.Done()
+			if err := sharedStateMachine.TransitionIf(Draft, Moderation); err != nil {
+				fmt.Printf("Request %d: %s\n", request, err) // Output: STATE DRAFT WAS EXPECTED BUT CURRENT STATE IS MODERATION
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	document.Reviewers = 2
+	sharedStateMachine.Fire(Approve, document)
+	sharedStateMachine.Fire(Publish, document)
+
+	fmt.Println(<-published, sharedStateMachine.String()) // Output: <nil> Published
+
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Zando74/generic-patterns/behavioral"
+)
+
+// A document still in Moderation after 48h is rejected
+func NewModeratedDocumentStateMachine(clock behavioral.Clock) (*behavioral.StateMachine, error) {
+
+	return (&behavioral.StateMachineBuilder{}).
+		SetCurrentState(Draft).
+		SetEventRules(&DocumentEventRules).
+		SetStateToString(&DocumentStateToString).
+		SetTerminalStates(Published).
+		AddTimeout(Moderation, 48*time.Hour, Rejected). // Leaving Moderation before 48h cancel the timeout
+		SetClock(clock).                                // The SystemClock is used by default
+		BuildStarted()                                  // Arm the timeouts of the initial state
+}
+
+func MainStateMachineTimeoutExample() {
+
+	clock := behavioral.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)) // Time only move when the clock is advanced
+	stateMachine, err := NewModeratedDocumentStateMachine(clock)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	stateMachine.Fire(Submit, nil) // Entering Moderation arm the timeout
+
+	clock.Advance(47 * time.Hour)
+	fmt.Println(stateMachine.String()) // Output: Moderation
+
+	clock.Advance(time.Hour)
+	fmt.Println(stateMachine.String()) // Output: Rejected
+
+	// A snapshot keep the time Moderation was entered, the timeout is re-armed for the remaining time on Restore
+	stateMachine.Fire(Rework, nil)
+	stateMachine.Fire(Submit, nil)
+	clock.Advance(40 * time.Hour)
+	snapshot := stateMachine.Snapshot()
+	stateMachine.StopTimeouts()
+
+	restoredStateMachine, err := NewModeratedDocumentStateMachine(clock)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	restoredStateMachine.Restore(snapshot)
+
+	clock.Advance(8 * time.Hour)
+	fmt.Println(restoredStateMachine.String()) // Output: Rejected
+
+	// With the SystemClock timeouts fire from their own goroutine, wrap the machine in a SyncStateMachine to share it
+
+}